@@ -15,6 +15,7 @@ import (
 	"github.com/BloggingApp/user-service/internal/repository/postgres"
 	"github.com/BloggingApp/user-service/internal/server"
 	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/BloggingApp/user-service/internal/sociallink"
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
@@ -67,10 +68,38 @@ func main() {
 	}
 	log.Println("Successfully connected to RabbitMQ")
 
+	var socialLinksConfig config.SocialLinksConfig
+	if err := viper.UnmarshalKey("social_links", &socialLinksConfig); err != nil {
+		log.Fatalf("failed to read social links config: %s", err.Error())
+	}
+	socialLinks, err := sociallink.NewRegistry(socialLinksConfig)
+	if err != nil {
+		log.Fatalf("failed to build social links registry: %s", err.Error())
+	}
+
+	socialLinkVerifier := sociallink.NewVerifier(sociallink.NewHTTPClient(socialLinksConfig.Verification.Timeout))
+
+	var suggestionsConfig config.SuggestionsConfig
+	if err := viper.UnmarshalKey("suggestions", &suggestionsConfig); err != nil {
+		log.Fatalf("failed to read suggestions config: %s", err.Error())
+	}
+
+	var followCountsConfig config.FollowCountsConfig
+	if err := viper.UnmarshalKey("follow_counts", &followCountsConfig); err != nil {
+		log.Fatalf("failed to read follow counts config: %s", err.Error())
+	}
+
 	repos := repository.New(db, rdb)
-	services := service.New(logger, repos, rabbitmq)
+	services := service.New(logger, repos, rabbitmq, socialLinks, socialLinkVerifier, socialLinksConfig.Verification, suggestionsConfig, followCountsConfig)
 	handlers := handler.New(services)
 
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	go services.SocialLinkVerification.Run(jobsCtx)
+	go services.Suggestions.Run(jobsCtx)
+	go services.FollowCounts.Run(jobsCtx)
+
 	srv := server.New()
 	serverConfig := config.ServerConfig{
 		Port: viper.GetString("app.port"),
@@ -21,3 +21,36 @@ type ServerConfig struct {
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 }
+
+type SocialLinksConfig struct {
+	MaxCount       int                          `mapstructure:"max_count"`
+	TrackingParams []string                     `mapstructure:"tracking_params"`
+	Platforms      []SocialLinkPlatformConfig   `mapstructure:"platforms"`
+	Verification   SocialLinkVerificationConfig `mapstructure:"verification"`
+}
+
+type SocialLinkPlatformConfig struct {
+	Name          string `mapstructure:"name"`
+	DisplayName   string `mapstructure:"display_name"`
+	Icon          string `mapstructure:"icon"`
+	Pattern       string `mapstructure:"pattern"`
+	StripQuery    bool   `mapstructure:"strip_query"`
+	LowercasePath bool   `mapstructure:"lowercase_path"`
+}
+
+type SocialLinkVerificationConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout"`
+	ScanInterval time.Duration `mapstructure:"scan_interval"`
+	RecheckAfter time.Duration `mapstructure:"recheck_after"`
+}
+
+type FollowCountsConfig struct {
+	FlushInterval     time.Duration `mapstructure:"flush_interval"`
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval"`
+}
+
+type SuggestionsConfig struct {
+	ComputeHour  int           `mapstructure:"compute_hour"`
+	Count        int           `mapstructure:"count"`
+	ActiveWithin time.Duration `mapstructure:"active_within"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// REPORT_EVENT_VERSION is bumped on breaking changes of ReportEvent.
+const REPORT_EVENT_VERSION = 1
+
+// ReportEvent is published to the moderation report queue when a user gets an open report.
+type ReportEvent struct {
+	Version           int       `json:"version"`
+	ReportID          uuid.UUID `json:"report_id"`
+	ReporterID        uuid.UUID `json:"reporter_id"`
+	TargetID          uuid.UUID `json:"target_id"`
+	Category          string    `json:"category"`
+	TargetOpenReports int64     `json:"target_open_reports"`
+	OccurredAt        time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// GetReportsReq is read from the query.
+type GetReportsReq struct {
+	Limit    int     `form:"limit" binding:"required,min=1"`
+	Offset   int     `form:"offset" binding:"min=0"`
+	Status   *string `form:"status" binding:"omitempty,oneof=open triaged resolved dismissed"`
+	Category *string `form:"category"`
+	TargetID *string `form:"target_id" binding:"omitempty,uuid"`
+}
+
+type TriageReportReq struct {
+	Note *string `json:"note"`
+}
+
+// ResolveReportReq closes the report, SuspendUntil suspends the reported user
+// and resolves the rest of the user's open reports too.
+type ResolveReportReq struct {
+	Status       string     `json:"status" binding:"required,oneof=resolved dismissed"`
+	Note         *string    `json:"note"`
+	SuspendUntil *time.Time `json:"suspend_until"`
+}
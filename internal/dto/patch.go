@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Patch is a single member of a JSON Merge Patch (RFC 7396) document:
+// an absent member leaves the field untouched, an explicit null clears it
+// and any other value replaces it.
+type Patch[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		p.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	p.Value = &value
+
+	return nil
+}
+
+// FieldErrors maps every rejected request field to the reason it was rejected.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + ": " + e[field]
+	}
+
+	return "invalid fields: " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPatchUnmarshalJSON(t *testing.T) {
+	type body struct {
+		Bio Patch[string] `json:"bio"`
+	}
+
+	tests := []struct {
+		name      string
+		data      string
+		wantSet   bool
+		wantValue *string
+		wantErr   bool
+	}{
+		{name: "absent", data: `{}`},
+		{name: "null", data: `{"bio": null}`, wantSet: true},
+		{name: "value", data: `{"bio": "hello"}`, wantSet: true, wantValue: ptr("hello")},
+		{name: "empty string", data: `{"bio": ""}`, wantSet: true, wantValue: ptr("")},
+		{name: "wrong type", data: `{"bio": 1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got body
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Bio.Set != tt.wantSet {
+				t.Errorf("Set = %v, want %v", got.Bio.Set, tt.wantSet)
+			}
+			if (got.Bio.Value == nil) != (tt.wantValue == nil) || (got.Bio.Value != nil && *got.Bio.Value != *tt.wantValue) {
+				t.Errorf("Value = %v, want %v", got.Bio.Value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileReqUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		want            func(r UpdateProfileReq) bool
+		wantFieldErrors []string
+		wantErr         bool
+	}{
+		{
+			name: "empty",
+			data: `{}`,
+			want: func(r UpdateProfileReq) bool { return r.IsEmpty() },
+		},
+		{
+			name: "set and clear",
+			data: `{"username": "alice", "bio": null, "is_private": true}`,
+			want: func(r UpdateProfileReq) bool {
+				return *r.Username.Value == "alice" && r.Bio.Set && r.Bio.Value == nil && *r.IsPrivate.Value && !r.DisplayName.Set
+			},
+		},
+		{
+			name: "unknown fields",
+			data: `{"email": "alice@blogging.app", "id": "1", "bio": "hello"}`,
+			wantFieldErrors: []string{"email", "id"},
+		},
+		{
+			name: "wrong types",
+			data: `{"display_name": 1, "is_private": "yes"}`,
+			wantFieldErrors: []string{"display_name", "is_private"},
+		},
+		{name: "not an object", data: `["bio"]`, wantErr: true},
+		{name: "null", data: `null`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got UpdateProfileReq
+			err := json.Unmarshal([]byte(tt.data), &got)
+
+			if tt.wantFieldErrors != nil {
+				fieldErrors, ok := err.(FieldErrors)
+				if !ok {
+					t.Fatalf("json.Unmarshal() error = %v, want FieldErrors", err)
+				}
+				if len(fieldErrors) != len(tt.wantFieldErrors) {
+					t.Errorf("FieldErrors = %v, want %v", fieldErrors, tt.wantFieldErrors)
+				}
+				for _, field := range tt.wantFieldErrors {
+					if _, ok := fieldErrors[field]; !ok {
+						t.Errorf("FieldErrors = %v, missing %q", fieldErrors, field)
+					}
+				}
+				return
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.want(got) {
+				t.Errorf("json.Unmarshal() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestFieldErrorsError(t *testing.T) {
+	err := FieldErrors{"username": "too short", "bio": "too long"}
+
+	if got, want := err.Error(), "invalid fields: bio: too long; username: too short"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -26,3 +26,19 @@ type RefreshResponse struct {
 	Ok          bool           `json:"ok"`
 	AccessToken string         `json:"access_token"`
 }
+
+type ValidationErrorResponse struct {
+	Ok        bool              `json:"ok"`
+	Details   string            `json:"details"`
+	Fields    map[string]string `json:"fields"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+func NewValidationErrorResponse(fields FieldErrors) ValidationErrorResponse {
+	return ValidationErrorResponse{
+		Ok: false,
+		Details: "some fields were rejected",
+		Fields: fields,
+		Timestamp: time.Now(),
+	}
+}
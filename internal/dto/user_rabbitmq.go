@@ -1,6 +1,58 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/google/uuid"
+)
+
 type RabbitMQNotificateUserCodeDto struct {
 	Email string `json:"email"`
 	Code  int    `json:"code"`
 }
+
+// FOLLOW_EVENT_VERSION is bumped on breaking changes of FollowEvent.
+// Version 2 replaced new_post_notifications_enabled with Notifications and PreviousNotifications.
+const FOLLOW_EVENT_VERSION = 2
+
+const (
+	FOLLOW_EVENT_FOLLOWED = "followed"
+	FOLLOW_EVENT_UNFOLLOWED = "unfollowed"
+	FOLLOW_EVENT_NOTIFICATIONS_UPDATED = "notifications_updated"
+)
+
+// Reasons of an "unfollowed" event that the follower didn't cause
+const (
+	FOLLOW_UNFOLLOWED_REASON_REMOVED = "removed"
+	FOLLOW_UNFOLLOWED_REASON_BLOCK = "block"
+)
+
+// FollowEvent is published to the follows topic exchange with the "follow.<type>" routing key.
+// An "unfollowed" follow that was ended by the followed user or by a block has a Reason.
+// A "notifications_updated" event carries the follower's preferences before and after the update.
+type FollowEvent struct {
+	Version               int                            `json:"version"`
+	Type                  string                         `json:"type"`
+	UserID                uuid.UUID                      `json:"user_id"`
+	FollowerID            uuid.UUID                      `json:"follower_id"`
+	Notifications         *model.NotificationPreferences `json:"notifications,omitempty"`
+	PreviousNotifications *model.NotificationPreferences `json:"previous_notifications,omitempty"`
+	Reason                string                         `json:"reason,omitempty"`
+	OccurredAt            time.Time                      `json:"occurred_at"`
+}
+
+func FollowEventRoutingKey(eventType string) string {
+	return "follow." + eventType
+}
+
+// BLOCK_EVENT_VERSION is bumped on breaking changes of BlockEvent.
+const BLOCK_EVENT_VERSION = 1
+
+// BlockEvent is published to the users.blocked and users.unblocked exchanges.
+type BlockEvent struct {
+	Version    int       `json:"version"`
+	UserID     uuid.UUID `json:"user_id"`
+	BlockedID  uuid.UUID `json:"blocked_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
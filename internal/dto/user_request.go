@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
 type CreateUserReq struct {
 	Email    string `json:"email" binding:"required,email"`
 	Username string `json:"username" binding:"required,min=3,max=20"`
@@ -11,14 +17,84 @@ type SignInReq struct {
 	Password        string `json:"password" binding:"required,min=3,max=48"`
 }
 
+// AvatarCropReq is an optional square, in pixels of the uploaded image, to cut the avatar from.
+type AvatarCropReq struct {
+	X    *int `form:"crop_x" binding:"omitempty,min=0"`
+	Y    *int `form:"crop_y" binding:"omitempty,min=0"`
+	Size *int `form:"crop_size" binding:"omitempty,min=1"`
+}
+
 type AddSocialLinkReq struct {
-	URL string `json:"url" binding:"required"`
+	URL   string  `json:"url" binding:"required"`
+	Label *string `json:"label"`
+}
+
+// UpdateSocialLinkReq is a JSON Merge Patch of a single social link.
+type UpdateSocialLinkReq struct {
+	URL   Patch[string] `json:"url"`
+	Label Patch[string] `json:"label"`
+}
+
+type ReorderSocialLinksReq struct {
+	Platforms []string `json:"platforms" binding:"required"`
 }
 
 type DeleteSocialLinkReq struct {
 	Platform string `json:"platform" binding:"required"`
 }
 
+// MuteReq mutes forever unless ExpiresAt is set.
+type MuteReq struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ReportReq struct {
+	Category string  `json:"category" binding:"required,oneof=spam harassment hate_speech impersonation inappropriate_content other"`
+	Text     *string `json:"text"`
+}
+
+// SearchUsersReq is read from the query.
+type SearchUsersReq struct {
+	Query  string `form:"q" binding:"required,max=64"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type SuggestionsReq struct {
+	Limit int `form:"limit" binding:"omitempty,min=1"`
+}
+
+// LimitOffsetReq is read from the query.
+type LimitOffsetReq struct {
+	Limit  int `form:"limit" binding:"required,min=1"`
+	Offset int `form:"offset" binding:"min=0"`
+}
+
+// RelationshipsReq lists comma-separated user IDs.
+type RelationshipsReq struct {
+	IDs string `form:"ids" binding:"required"`
+}
+
+// UpdateNotificationsReq changes only the preferences it sets, Channels can't be emptied,
+// the "none" level turns the notifications off.
+type UpdateNotificationsReq struct {
+	Level    *string   `json:"level" binding:"omitempty,oneof=all highlighted none"`
+	Channels *[]string `json:"channels" binding:"omitempty,min=1,max=3,unique,dive,oneof=email in_app push"`
+	Digest   *string   `json:"digest" binding:"omitempty,oneof=immediate daily weekly"`
+}
+
+// NotifiedFollowersReq is read from the query, followers that want only highlighted
+// posts are notified only about highlighted ones.
+type NotifiedFollowersReq struct {
+	Highlighted bool `form:"highlighted"`
+}
+
+// FollowsPageReq is read from the query, the first page is requested without a cursor.
+type FollowsPageReq struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+	Cursor string `form:"cursor"`
+}
+
 type UpdatePasswordReq struct {
 	OldPassword string `json:"old_password" binding:"required,min=8"`
 	NewPassword string `json:"new_password" binding:"required,min=8"`
@@ -32,3 +108,58 @@ type ChangeForgottenPasswordReq struct {
 	Code        int    `json:"code" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
+
+// UpdateProfileReq is a JSON Merge Patch of the user's profile.
+type UpdateProfileReq struct {
+	Username    Patch[string]
+	DisplayName Patch[string]
+	Bio         Patch[string]
+	IsPrivate   Patch[bool]
+}
+
+func (r *UpdateProfileReq) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	if members == nil {
+		return errors.New("merge patch must be a JSON object")
+	}
+
+	fieldErrors := FieldErrors{}
+	for name, value := range members {
+		if name == "is_private" {
+			if err := r.IsPrivate.UnmarshalJSON(value); err != nil {
+				fieldErrors[name] = "must be a boolean"
+			}
+			continue
+		}
+
+		var field *Patch[string]
+		switch name {
+		case "username":
+			field = &r.Username
+		case "display_name":
+			field = &r.DisplayName
+		case "bio":
+			field = &r.Bio
+		default:
+			fieldErrors[name] = "field is unknown or cannot be updated"
+			continue
+		}
+
+		if err := field.UnmarshalJSON(value); err != nil {
+			fieldErrors[name] = "must be a string or null"
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	return nil
+}
+
+func (r UpdateProfileReq) IsEmpty() bool {
+	return !r.Username.Set && !r.DisplayName.Set && !r.Bio.Set && !r.IsPrivate.Set
+}
@@ -8,17 +8,25 @@ import (
 )
 
 type GetUserDto struct {
-	ID                          uuid.UUID           `json:"id"`
-	Username                    string              `json:"username"`
-	DisplayName                 *string             `json:"display_name"`
-	AvatarURL                   *string             `json:"avatar_url"`
-	Bio                         *string             `json:"bio"`
-	Followers                   int64               `json:"followers"`
-	CreatedAt                   time.Time           `json:"created_at"`
-	UpdatedAt                   time.Time           `json:"updated_at"`
-	SocialLinks                 []*model.SocialLink `json:"social_links"`
-	IsFollowing                 bool                `json:"is_following"`
-	NewPostNotificationsEnabled bool                `json:"new_post_notifications_enabled"`
+	ID              uuid.UUID                      `json:"id"`
+	Username        string                         `json:"username"`
+	DisplayName     *string                        `json:"display_name"`
+	AvatarURL       *string                        `json:"avatar_url"`
+	AvatarURLs      map[string]string              `json:"avatar_urls"`
+	BannerURL       *string                        `json:"banner_url"`
+	Bio             *string                        `json:"bio"`
+	IsPrivate       bool                           `json:"is_private"`
+	Followers       int64                          `json:"followers"`
+	Follows         int64                          `json:"follows"`
+	CreatedAt       time.Time                      `json:"created_at"`
+	UpdatedAt       time.Time                      `json:"updated_at"`
+	SocialLinks     []*model.SocialLink            `json:"social_links"`
+	IsFollowing     bool                           `json:"is_following"`
+	IsFollowedBy    bool                           `json:"is_followed_by"`
+	FollowRequested bool                           `json:"follow_requested"`
+	Notifications   *model.NotificationPreferences `json:"notifications"`
+	IsBlocking      bool                           `json:"is_blocking"`
+	IsMuted         bool                           `json:"is_muted"`
 }
 
 func GetUserDtoFromFullUser(fullUser model.FullUser) *GetUserDto {
@@ -27,14 +35,70 @@ func GetUserDtoFromFullUser(fullUser model.FullUser) *GetUserDto {
 		Username: fullUser.Username,
 		DisplayName: fullUser.DisplayName,
 		AvatarURL: fullUser.AvatarURL,
+		AvatarURLs: fullUser.AvatarURLs,
+		BannerURL: fullUser.BannerURL,
 		Bio: fullUser.Bio,
+		IsPrivate: fullUser.IsPrivate,
 		Followers: fullUser.Followers,
+		Follows: fullUser.Follows,
 		CreatedAt: fullUser.CreatedAt,
 		UpdatedAt: fullUser.UpdatedAt,
 		SocialLinks: fullUser.SocialLinks,
 		IsFollowing: fullUser.IsFollowing,
-		NewPostNotificationsEnabled: fullUser.NewPostNotificationsEnabled,
+		FollowRequested: fullUser.FollowRequested,
+		Notifications: fullUser.Notifications,
+		IsBlocking: fullUser.IsBlocking,
 	}
 }
 
-type GetUserFollowersDto []*model.FullFollower
+// FollowsPage is a page of a followers or follows list, NextCursor is nil on the last page.
+type FollowsPage struct {
+	Items      []*model.FullFollower `json:"items"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+// NotifiedFollowersEnd is the last line of a complete notified followers stream,
+// a stream that failed midway ends with a NotifiedFollowersError line instead.
+type NotifiedFollowersEnd struct {
+	Done  bool  `json:"done"`
+	Count int64 `json:"count"`
+}
+
+type NotifiedFollowersError struct {
+	Error string `json:"error"`
+}
+
+// MutualFollowers are the followers of a user that the viewer follows.
+type MutualFollowers struct {
+	Count  int64                 `json:"count"`
+	Sample []*model.FullFollower `json:"sample"`
+}
+
+// NewFollowsPage cuts a page of limit follows, follows holds more than limit
+// of them when there is a next page.
+func NewFollowsPage(follows []*model.FullFollower, limit int) *FollowsPage {
+	page := &FollowsPage{
+		Items: follows,
+	}
+	if page.Items == nil {
+		page.Items = []*model.FullFollower{}
+	}
+
+	if len(follows) > limit {
+		page.Items = follows[:limit]
+		nextCursor := page.Items[limit-1].Cursor().Encode()
+		page.NextCursor = &nextCursor
+	}
+
+	return page
+}
+
+// SetRelationship overlays the viewer's relationship on the viewer-independent profile.
+func (u *GetUserDto) SetRelationship(relationship model.Relationship) {
+	u.IsFollowing = relationship.IsFollowing
+	u.IsFollowedBy = relationship.IsFollowedBy
+	u.FollowRequested = relationship.FollowRequested
+	u.Notifications = relationship.Notifications
+	u.IsBlocking = relationship.IsBlocking
+	u.IsMuted = relationship.IsMuted
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// adminMiddleware must run after authMiddleware.
+func (h *Handler) adminMiddleware(c *gin.Context) {
+	user := h.getUser(c)
+	if user == nil || user.Role != model.ROLE_ADMIN {
+		c.JSON(http.StatusForbidden, dto.NewBasicResponse(false, errAdminOnly.Error()))
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
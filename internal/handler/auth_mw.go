@@ -3,8 +3,10 @@ package handler
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,6 +32,12 @@ func (h *Handler) authMiddleware(c *gin.Context) {
 		return
 	}
 
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		c.JSON(http.StatusForbidden, dto.NewBasicResponse(false, service.ErrAccountSuspended.Error()))
+		c.Abort()
+		return
+	}
+
 	c.Set("user", *user)
 
 	c.Next()
@@ -8,4 +8,8 @@ var (
 	errInvalidUsername = errors.New("invalid username, it should start with: '@'")
 	errInvalidID = errors.New("provided an invalid ID")
 	errInvalidRequestBody = errors.New("invalid request body")
+	errIfMatchRequired = errors.New("please provide If-Match header with the profile's ETag")
+	errAdminOnly = errors.New("only administrators can do this")
+	errInvalidInternalToken = errors.New("invalid internal token")
+	errInvalidIfMatch = errors.New("If-Match header must contain a strong ETag of the profile")
 )
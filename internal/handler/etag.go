@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// userETag is a strong validator of the user's own profile version, it is what
+// PATCH /@me/update expects in If-Match.
+func userETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// meETag is userETag extended with the follow counts, which change the profile
+// as returned by GET /@me without bumping its updated_at. If-Match accepts it too.
+func meETag(user *model.FullUser) string {
+	return `"` + strconv.FormatInt(user.UpdatedAt.UnixMicro(), 10) + "." + strconv.FormatInt(user.Followers, 10) + "." + strconv.FormatInt(user.Follows, 10) + `"`
+}
+
+// parseUserETag extracts the profile version from a userETag or a meETag.
+func parseUserETag(etag string) (time.Time, bool) {
+	etag = strings.TrimSpace(etag)
+	if strings.HasPrefix(etag, "W/") || len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return time.Time{}, false
+	}
+
+	version, _, _ := strings.Cut(etag[1:len(etag)-1], ".")
+	micros, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.UnixMicro(micros).UTC(), true
+}
+
+// userDtoETag is a weak validator of a profile as seen by the getter, so it also
+// covers the follow counts and the getter's follow state.
+func userDtoETag(user *dto.GetUserDto) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf(
+		"%s:%d:%d:%d:%t:%t:%t:%s:%t:%t",
+		user.ID.String(),
+		user.UpdatedAt.UnixMicro(),
+		user.Followers,
+		user.Follows,
+		user.IsFollowing,
+		user.IsFollowedBy,
+		user.FollowRequested,
+		notificationsETag(user.Notifications),
+		user.IsBlocking,
+		user.IsMuted,
+	)))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func notificationsETag(notifications *model.NotificationPreferences) string {
+	if notifications == nil {
+		return ""
+	}
+
+	return notifications.Level + "/" + strings.Join(notifications.Channels, ",") + "/" + notifications.Digest
+}
+
+// notModified sets the ETag header and answers 304 when If-None-Match matches it.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatchVersion extracts the updated_at the client has last seen from If-Match,
+// nil means that any version matches. current is preferred when the header lists
+// several entity tags.
+func ifMatchVersion(c *gin.Context, current time.Time) (*time.Time, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		return nil, errIfMatchRequired
+	}
+
+	if ifMatch == "*" {
+		return nil, nil
+	}
+
+	var versions []time.Time
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if version, ok := parseUserETag(candidate); ok {
+			if version.Equal(current) {
+				return &version, nil
+			}
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, errInvalidIfMatch
+	}
+
+	return &versions[0], nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for key, value := range headers {
+		c.Request.Header.Set(key, value)
+	}
+
+	return c, recorder
+}
+
+func TestParseUserETag(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 8, 0, 0, 123456000, time.UTC)
+	user := &model.FullUser{UpdatedAt: updatedAt, Followers: 12, Follows: 3}
+
+	tests := []struct {
+		name   string
+		etag   string
+		want   time.Time
+		wantOk bool
+	}{
+		{name: "userETag", etag: userETag(updatedAt), want: updatedAt, wantOk: true},
+		{name: "meETag", etag: meETag(user), want: updatedAt, wantOk: true},
+		{name: "surrounding spaces", etag: "  " + userETag(updatedAt) + " ", want: updatedAt, wantOk: true},
+		{name: "weak", etag: "W/" + userETag(updatedAt)},
+		{name: "unquoted", etag: "1760860800123456"},
+		{name: "not a number", etag: `"abc"`},
+		{name: "empty", etag: ""},
+		{name: "quote only", etag: `"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseUserETag(tt.etag)
+			if ok != tt.wantOk {
+				t.Fatalf("parseUserETag(%q) ok = %v, want %v", tt.etag, ok, tt.wantOk)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("parseUserETag(%q) = %v, want %v", tt.etag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeETagCoversFollowCounts(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	etag := meETag(&model.FullUser{UpdatedAt: updatedAt, Followers: 12, Follows: 3})
+	if etag == meETag(&model.FullUser{UpdatedAt: updatedAt, Followers: 13, Follows: 3}) {
+		t.Error("meETag() is the same for different followers counts")
+	}
+	if etag == meETag(&model.FullUser{UpdatedAt: updatedAt, Followers: 12, Follows: 4}) {
+		t.Error("meETag() is the same for different follows counts")
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	current := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	stale := current.Add(-time.Minute)
+	user := &model.FullUser{UpdatedAt: current, Followers: 5}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    *time.Time
+		wantErr error
+	}{
+		{name: "missing", ifMatch: "", wantErr: errIfMatchRequired},
+		{name: "any", ifMatch: "*", want: nil},
+		{name: "current", ifMatch: userETag(current), want: &current},
+		{name: "current meETag", ifMatch: meETag(user), want: &current},
+		{name: "stale", ifMatch: userETag(stale), want: &stale},
+		{name: "current preferred", ifMatch: userETag(stale) + ", " + userETag(current), want: &current},
+		{name: "invalid ones skipped", ifMatch: `"abc", ` + userETag(stale), want: &stale},
+		{name: "weak", ifMatch: "W/" + userETag(current), wantErr: errInvalidIfMatch},
+		{name: "garbage", ifMatch: "garbage", wantErr: errInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestContext(map[string]string{"If-Match": tt.ifMatch})
+
+			got, err := ifMatchVersion(c, current)
+			if err != tt.wantErr {
+				t.Fatalf("ifMatchVersion() error = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("ifMatchVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := userETag(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "missing", ifNoneMatch: "", want: false},
+		{name: "same", ifNoneMatch: etag, want: true},
+		{name: "weak comparison", ifNoneMatch: "W/" + etag, want: true},
+		{name: "among others", ifNoneMatch: `"1", ` + etag, want: true},
+		{name: "any", ifNoneMatch: "*", want: true},
+		{name: "other", ifNoneMatch: `"1"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, recorder := newTestContext(map[string]string{"If-None-Match": tt.ifNoneMatch})
+
+			if got := notModified(c, etag); got != tt.want {
+				t.Fatalf("notModified() = %v, want %v", got, tt.want)
+			}
+			c.Writer.WriteHeaderNow()
+			if tt.want && recorder.Code != http.StatusNotModified {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusNotModified)
+			}
+			if recorder.Header().Get("ETag") != etag {
+				t.Errorf("ETag = %q, want %q", recorder.Header().Get("ETag"), etag)
+			}
+		})
+	}
+}
+
+
+func TestUsersUpdatePreconditions(t *testing.T) {
+	current := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	updated := current.Add(time.Second)
+
+	// The service fails the precondition like postgres does, when the version isn't the stored one
+	h := New(&service.Service{User: &fakeUserService{update: func(ifUpdatedAt *time.Time) (time.Time, error) {
+		if ifUpdatedAt != nil && !ifUpdatedAt.Equal(current) {
+			return time.Time{}, service.ErrPreconditionFailed
+		}
+		return updated, nil
+	}}})
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		want     int
+		wantETag string
+	}{
+		{name: "missing", ifMatch: "", want: http.StatusPreconditionRequired},
+		{name: "malformed", ifMatch: "garbage", want: http.StatusBadRequest},
+		{name: "stale", ifMatch: userETag(current.Add(-time.Minute)), want: http.StatusPreconditionFailed},
+		{name: "current", ifMatch: userETag(current), want: http.StatusOK, wantETag: userETag(updated)},
+		{name: "any", ifMatch: "*", want: http.StatusOK, wantETag: userETag(updated)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, recorder := newTestContext(map[string]string{"If-Match": tt.ifMatch})
+			c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"bio": "hello"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			c.Set("user", model.FullUser{UpdatedAt: current})
+
+			h.usersUpdate(c)
+
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body.String())
+			}
+			if recorder.Header().Get("ETag") != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", recorder.Header().Get("ETag"), tt.wantETag)
+			}
+		})
+	}
+}
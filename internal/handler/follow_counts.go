@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) adminReconcileFollowCounts(c *gin.Context) {
+	if err := h.services.FollowCounts.StartReconciliation(c.Request.Context()); err != nil {
+		if err == service.ErrReconciliationRunning {
+			c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.NewBasicResponse(true, "follow counts reconciliation started"))
+}
+
+func (h *Handler) adminGetFollowCountsReconciliation(c *gin.Context) {
+	report, err := h.services.FollowCounts.LastReconciliation(c.Request.Context())
+	if err != nil {
+		if err == service.ErrNoReconciliation {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
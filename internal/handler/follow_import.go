@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) usersImportFollows(c *gin.Context) {
+	user := h.getUser(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MAX_FOLLOW_IMPORT_SIZE + (1 << 20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	followImport, err := h.services.User.ImportFollows(c.Request.Context(), *user, fileHeader)
+	if err != nil {
+		switch err {
+		case service.ErrFollowImportTooLarge:
+			c.JSON(http.StatusRequestEntityTooLarge, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrInvalidFollowImport:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrFollowImportRunning:
+			c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, followImport)
+}
+
+func (h *Handler) usersGetFollowImport(c *gin.Context) {
+	user := h.getUser(c)
+
+	importID, err := uuid.Parse(strings.TrimSpace(c.Param("importID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	followImport, err := h.services.User.FindFollowImport(c.Request.Context(), user.ID, importID)
+	if err != nil {
+		if err == service.ErrFollowImportNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, followImport)
+}
+
+func (h *Handler) usersExportFollowers(c *gin.Context) {
+	h.exportFollows(c, "followers.csv", h.services.User.ExportFollowers)
+}
+
+func (h *Handler) usersExportFollows(c *gin.Context) {
+	h.exportFollows(c, "follows.csv", h.services.User.ExportFollows)
+}
+
+// exportFollows streams the follows as CSV a page at a time, an error in the middle
+// of the stream can't change the status anymore, so it cuts the file short.
+func (h *Handler) exportFollows(c *gin.Context, filename string, export func(ctx context.Context, userID uuid.UUID, write func(follows []*model.FullFollower) error) error) {
+	user := h.getUser(c)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="` + filename + `"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"username", "display_name", "followed_at"})
+
+	if err := export(c.Request.Context(), user.ID, func(follows []*model.FullFollower) error {
+		for _, follow := range follows {
+			var displayName, followedAt string
+			if follow.DisplayName != nil {
+				displayName = *follow.DisplayName
+			}
+			if follow.FollowedAt != nil {
+				followedAt = follow.FollowedAt.UTC().Format(time.RFC3339)
+			}
+
+			writer.Write([]string{csvSafe(follow.Username), csvSafe(displayName), followedAt})
+		}
+
+		writer.Flush()
+		c.Writer.Flush()
+		return writer.Error()
+	}); err != nil {
+		c.Error(err)
+		return
+	}
+
+	writer.Flush()
+}
+
+// csvSafe keeps spreadsheets from evaluating a user controlled value as a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,27 @@
+package handler
+
+import "testing"
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "alice", want: "alice"},
+		{value: "", want: ""},
+		{value: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "\r=1", want: "'\r=1"},
+		{value: "a=1", want: "a=1"},
+		{value: " =1", want: " =1"},
+	}
+
+	for _, tt := range tests {
+		if got := csvSafe(tt.value); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
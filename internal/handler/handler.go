@@ -29,6 +29,8 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{viper.GetString("client.origin")},
 		AllowMethods: []string{"POST", "GET", "PATCH", "PUT", "DELETE"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Authorization", "If-Match", "If-None-Match"},
+		ExposeHeaders: []string{"ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -56,25 +58,82 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 				me.GET("", h.usersMe)
 				me.GET("/followers", h.usersGetFollowers)
+				me.GET("/followers/export", h.usersExportFollowers)
+				me.DELETE("/followers/:followerID", h.usersRemoveFollower)
 				me.GET("/follows", h.usersGetFollows)
+				me.GET("/follows/export", h.usersExportFollows)
+				me.POST("/follows/import", h.usersImportFollows)
+				me.GET("/follows/import/:importID", h.usersGetFollowImport)
+				me.GET("/mutes", h.usersGetMutes)
+				me.GET("/suggestions", h.usersGetSuggestions)
+				me.DELETE("/suggestions/:userID", h.usersDismissSuggestion)
+
+				followRequests := me.Group("/follow-requests")
+				{
+					followRequests.GET("", h.usersGetFollowRequests)
+					followRequests.PUT("/:followerID", h.usersApproveFollowRequest)
+					followRequests.DELETE("/:followerID", h.usersRejectFollowRequest)
+				}
 
 				update := me.Group("/update")
 				{
 					update.PATCH("", h.usersUpdate)
 					update.PATCH("/setAvatar", h.usersSetAvatar)
+					update.DELETE("/setAvatar", h.usersRemoveAvatar)
+					update.PATCH("/setBanner", h.usersSetBanner)
 
 					socialLinks := update.Group("/socialLinks")
 					{
 						socialLinks.PUT("", h.usersAddSocialLink)
 						socialLinks.DELETE("", h.usersDeleteSocialLink)
+						socialLinks.PATCH("/:platform", h.usersUpdateSocialLink)
+						socialLinks.PUT("/order", h.usersReorderSocialLinks)
 					}
 				}
 			}
 
 			users.GET("/byUsername/:username", h.authMiddleware, h.usernameMiddleware, h.usersGetByUsername)
+			users.GET("/search", h.authMiddleware, h.usersSearch)
+			users.GET("/relationships", h.authMiddleware, h.usersGetRelationships)
+			users.GET("/:userID/relationship", h.authMiddleware, h.usersGetRelationship)
+			users.GET("/:userID/followers", h.authMiddleware, h.usersGetUserFollowers)
+			users.GET("/:userID/follows", h.authMiddleware, h.usersGetUserFollows)
+			users.GET("/:userID/mutual-followers", h.authMiddleware, h.usersGetMutualFollowers)
 			users.PUT("/:userID/follow", h.authMiddleware, h.usersFollow)
 			users.DELETE("/:userID/unfollow", h.authMiddleware, h.usersUnfollow)
-			users.PATCH("/:userID/notifications", h.authMiddleware, h.usersUpdateNewPostNotificationsEnabled)
+			users.PATCH("/:userID/notifications", h.authMiddleware, h.usersUpdateNotifications)
+			users.PUT("/:userID/block", h.authMiddleware, h.usersBlock)
+			users.DELETE("/:userID/block", h.authMiddleware, h.usersUnblock)
+			users.PUT("/:userID/mute", h.authMiddleware, h.usersMute)
+			users.DELETE("/:userID/mute", h.authMiddleware, h.usersUnmute)
+			users.POST("/:userID/report", h.authMiddleware, h.usersReport)
+		}
+
+		admin := v1.Group("/admin")
+		{
+			admin.Use(h.authMiddleware, h.adminMiddleware)
+
+			reports := admin.Group("/reports")
+			{
+				reports.GET("", h.adminGetReports)
+				reports.PATCH("/:reportID/triage", h.adminTriageReport)
+				reports.PATCH("/:reportID/resolve", h.adminResolveReport)
+			}
+
+			admin.DELETE("/users/:userID/suspension", h.adminLiftSuspension)
+
+			followCounts := admin.Group("/follow-counts")
+			{
+				followCounts.POST("/reconcile", h.adminReconcileFollowCounts)
+				followCounts.GET("/reconcile", h.adminGetFollowCountsReconciliation)
+			}
+		}
+
+		internal := v1.Group("/internal")
+		{
+			internal.Use(h.internalMiddleware)
+
+			internal.GET("/users/:userID/notified-followers", h.internalStreamNotifiedFollowers)
 		}
 	}
 
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NOTIFIED_FOLLOWERS_WRITE_TIMEOUT is how long a batch of the notified followers
+// stream may take to be written, a slower reader is cut off so that it doesn't hold
+// the postgres cursor open.
+const NOTIFIED_FOLLOWERS_WRITE_TIMEOUT = time.Second * 30
+
+// internalStreamNotifiedFollowers streams the followers to notify about the user's
+// new post as NDJSON, one {"follower_id": "...", "channels": [...], "digest": "..."} per
+// line, followed by {"done": true, "count": N}. The consumers treat a stream without
+// it as failed, it ends with {"error": "..."} when the failure could still be written.
+func (h *Handler) internalStreamNotifiedFollowers(c *gin.Context) {
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.NotifiedFollowersReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if _, err := h.services.User.FindByID(c.Request.Context(), userID); err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	// The stream of a popular user outlasts the server's write timeout, every
+	// batch gets its own deadline instead
+	controller := http.NewResponseController(c.Writer)
+	controller.SetWriteDeadline(time.Now().Add(NOTIFIED_FOLLOWERS_WRITE_TIMEOUT))
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	writer := bufio.NewWriter(c.Writer)
+	encoder := json.NewEncoder(writer)
+
+	var count int64
+	if err := h.services.User.StreamNotifiedFollowers(c.Request.Context(), userID, input.Highlighted, func(followers []*model.NotifiedFollower) error {
+		controller.SetWriteDeadline(time.Now().Add(NOTIFIED_FOLLOWERS_WRITE_TIMEOUT))
+
+		for _, follower := range followers {
+			if err := encoder.Encode(follower); err != nil {
+				return err
+			}
+		}
+		count += int64(len(followers))
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}); err != nil {
+		c.Error(err)
+
+		encoder.Encode(dto.NotifiedFollowersError{Error: err.Error()})
+		writer.Flush()
+		return
+	}
+
+	controller.SetWriteDeadline(time.Now().Add(NOTIFIED_FOLLOWERS_WRITE_TIMEOUT))
+	encoder.Encode(dto.NotifiedFollowersEnd{Done: true, Count: count})
+	writer.Flush()
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+const INTERNAL_TOKEN_HEADER = "X-Internal-Token"
+
+// internalMiddleware lets in the other services of the app, which send INTERNAL_API_TOKEN
+// in X-Internal-Token. The internal API is closed when the token isn't set.
+func (h *Handler) internalMiddleware(c *gin.Context) {
+	token := os.Getenv("INTERNAL_API_TOKEN")
+	given := c.GetHeader(INTERNAL_TOKEN_HEADER)
+	if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, dto.NewBasicResponse(false, errInvalidInternalToken.Error()))
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
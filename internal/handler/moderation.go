@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) usersReport(c *gin.Context) {
+	user := h.getUser(c)
+
+	targetID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.ReportReq
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Moderation.Report(c.Request.Context(), *user, targetID, input); err != nil {
+		h.moderationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) adminGetReports(c *gin.Context) {
+	var input dto.GetReportsReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	filter := model.ReportsFilter{
+		Status: input.Status,
+		Category: input.Category,
+	}
+	if input.TargetID != nil {
+		targetID, err := uuid.Parse(*input.TargetID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+			return
+		}
+		filter.TargetID = &targetID
+	}
+
+	reports, err := h.services.Moderation.FindReports(c.Request.Context(), filter, input.Limit, input.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *Handler) adminTriageReport(c *gin.Context) {
+	moderator := h.getUser(c)
+
+	reportID, err := uuid.Parse(strings.TrimSpace(c.Param("reportID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.TriageReportReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+	}
+
+	if err := h.services.Moderation.TriageReport(c.Request.Context(), *moderator, reportID, input); err != nil {
+		h.moderationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) adminResolveReport(c *gin.Context) {
+	moderator := h.getUser(c)
+
+	reportID, err := uuid.Parse(strings.TrimSpace(c.Param("reportID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.ResolveReportReq
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Moderation.ResolveReport(c.Request.Context(), *moderator, reportID, input); err != nil {
+		h.moderationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) adminLiftSuspension(c *gin.Context) {
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.Moderation.LiftSuspension(c.Request.Context(), userID); err != nil {
+		h.moderationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) moderationError(c *gin.Context, err error) {
+	var fieldErrors dto.FieldErrors
+	if errors.As(err, &fieldErrors) {
+		c.JSON(http.StatusUnprocessableEntity, dto.NewValidationErrorResponse(fieldErrors))
+		return
+	}
+
+	switch err {
+	case service.ErrUserNotFound, service.ErrReportNotFound:
+		c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+	case service.ErrReportYourself:
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+	case service.ErrReportAlreadyClosed, service.ErrInvalidReportTransition:
+		c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	}
+}
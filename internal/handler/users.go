@@ -1,23 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/BloggingApp/user-service/internal/dto"
 	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-type limitOffsetInput struct {
-	Limit  int `json:"limit" binding:"required"`
-	Offset int `json:"offset" binding:"min=0"`
-}
-
 func (h *Handler) usersMe(c *gin.Context) {
 	user := h.getUser(c)
 
+	if err := h.services.User.AddPendingFollowCounts(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if notModified(c, meETag(user)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, user)
 }
 
@@ -28,29 +34,248 @@ func (h *Handler) usersGetByUsername(c *gin.Context) {
 
 	result, err := h.services.User.FindByUsername(c.Request.Context(), &user.ID, username)
 	if err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
+	if notModified(c, userDtoETag(result)) {
+		return
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *Handler) usersSearch(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.SearchUsersReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	results, err := h.services.User.SearchUsers(c.Request.Context(), &user.ID, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		if err == service.ErrEmptySearchQuery {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
 func (h *Handler) usersGetFollowers(c *gin.Context) {
 	user := h.getUser(c)
 
-	var input limitOffsetInput
-	if err := c.ShouldBindJSON(&input); err != nil {
+	var input dto.FollowsPageReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	page, err := h.services.User.FindUserFollowers(c.Request.Context(), user.ID, input.Limit, input.Cursor)
+	if err != nil {
+		if err == service.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) usersGetSuggestions(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.SuggestionsReq
+	if err := c.ShouldBindQuery(&input); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	subs, err := h.services.User.FindUserFollowers(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	suggestions, err := h.services.Suggestions.FindSuggestions(c.Request.Context(), user.ID, input.Limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, subs)
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *Handler) usersDismissSuggestion(c *gin.Context) {
+	user := h.getUser(c)
+
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.Suggestions.DismissSuggestion(c.Request.Context(), user.ID, userID); err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersGetRelationship(c *gin.Context) {
+	user := h.getUser(c)
+
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	relationship, err := h.services.User.FindRelationship(c.Request.Context(), user.ID, userID)
+	if err != nil {
+		switch err {
+		case service.ErrRelationshipWithYourself:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, relationship)
+}
+
+func (h *Handler) usersGetRelationships(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.RelationshipsReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	var userIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, rawID := range strings.Split(input.IDs, ",") {
+		userID, err := uuid.Parse(strings.TrimSpace(rawID))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+			return
+		}
+
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	relationships, err := h.services.User.FindRelationships(c.Request.Context(), user.ID, userIDs)
+	if err != nil {
+		if err == service.ErrTooManyIDs {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, relationships)
+}
+
+func (h *Handler) usersGetUserFollowers(c *gin.Context) {
+	viewer := h.getUser(c)
+
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.FollowsPageReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	page, err := h.services.User.ViewUserFollowers(c.Request.Context(), viewer.ID, userID, input.Limit, input.Cursor)
+	if err != nil {
+		h.followsPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) usersGetUserFollows(c *gin.Context) {
+	viewer := h.getUser(c)
+
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.FollowsPageReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	page, err := h.services.User.ViewUserFollows(c.Request.Context(), viewer.ID, userID, input.Limit, input.Cursor)
+	if err != nil {
+		h.followsPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) usersGetMutualFollowers(c *gin.Context) {
+	viewer := h.getUser(c)
+
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	mutualFollowers, err := h.services.User.FindMutualFollowers(c.Request.Context(), viewer.ID, userID)
+	if err != nil {
+		h.followsPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mutualFollowers)
+}
+
+func (h *Handler) followsPageError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidCursor:
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+	case service.ErrPrivateAccount:
+		c.JSON(http.StatusForbidden, dto.NewBasicResponse(false, err.Error()))
+	case service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	}
 }
 
 func (h *Handler) usersFollow(c *gin.Context) {
@@ -63,8 +288,25 @@ func (h *Handler) usersFollow(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.User.Follow(c.Request.Context(), model.Follower{FollowerID: follower.ID, UserID: userID}); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	requested, err := h.services.User.Follow(c.Request.Context(), model.Follower{FollowerID: follower.ID, UserID: userID})
+	if err != nil {
+		switch err {
+		case service.ErrFollowToYourself:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrFollowBlocked:
+			c.JSON(http.StatusForbidden, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrAlreadyFollowing:
+			c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		}
+		return
+	}
+
+	if requested {
+		c.JSON(http.StatusAccepted, dto.NewBasicResponse(true, "follow request sent"))
 		return
 	}
 
@@ -89,11 +331,202 @@ func (h *Handler) usersUnfollow(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
-type usersUpdateNewPostNotificationsEnabledRequest struct {
-	Value bool `json:"value" binding:"required"`
+func (h *Handler) usersRemoveFollower(c *gin.Context) {
+	user := h.getUser(c)
+
+	followerID, err := uuid.Parse(strings.TrimSpace(c.Param("followerID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.RemoveFollower(c.Request.Context(), *user, followerID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersBlock(c *gin.Context) {
+	user := h.getUser(c)
+
+	blockedID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.Block(c.Request.Context(), *user, blockedID); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		case service.ErrBlockYourself:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		case service.ErrAlreadyBlocked:
+			c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersUnblock(c *gin.Context) {
+	user := h.getUser(c)
+
+	blockedID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.Unblock(c.Request.Context(), *user, blockedID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersMute(c *gin.Context) {
+	user := h.getUser(c)
+
+	mutedID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.MuteReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+	}
+
+	if err := h.services.User.Mute(c.Request.Context(), *user, mutedID, input.ExpiresAt); err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		if err == service.ErrMuteYourself || err == service.ErrMuteExpiresInPast {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersUnmute(c *gin.Context) {
+	user := h.getUser(c)
+
+	mutedID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.Unmute(c.Request.Context(), *user, mutedID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersGetMutes(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.LimitOffsetReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	mutes, err := h.services.User.FindMutes(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, mutes)
+}
+
+func (h *Handler) usersGetFollowRequests(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.LimitOffsetReq
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	requests, err := h.services.User.FindFollowRequests(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+func (h *Handler) usersApproveFollowRequest(c *gin.Context) {
+	user := h.getUser(c)
+
+	followerID, err := uuid.Parse(strings.TrimSpace(c.Param("followerID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.ApproveFollowRequest(c.Request.Context(), *user, followerID); err != nil {
+		if err == service.ErrFollowRequestNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersRejectFollowRequest(c *gin.Context) {
+	user := h.getUser(c)
+
+	followerID, err := uuid.Parse(strings.TrimSpace(c.Param("followerID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.User.RejectFollowRequest(c.Request.Context(), *user, followerID); err != nil {
+		if err == service.ErrFollowRequestNotFound {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
-func (h *Handler) usersUpdateNewPostNotificationsEnabled(c *gin.Context) {
+func (h *Handler) usersUpdateNotifications(c *gin.Context) {
 	follower := h.getUser(c)
 
 	userIDString := strings.TrimSpace(c.Param("userID"))
@@ -103,69 +536,137 @@ func (h *Handler) usersUpdateNewPostNotificationsEnabled(c *gin.Context) {
 		return
 	}
 
-	var req usersUpdateNewPostNotificationsEnabledRequest
+	var req dto.UpdateNotificationsReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	if err := h.services.User.UpdateNewPostNotificationsEnabled(c.Request.Context(), model.Follower{
+	update := model.NotificationPreferencesUpdate{
+		Level: req.Level,
+		Digest: req.Digest,
+	}
+	if req.Channels != nil {
+		update.Channels = *req.Channels
+	}
+
+	preferences, err := h.services.User.UpdateNotificationPreferences(c.Request.Context(), model.Follower{
 		FollowerID: follower.ID,
 		UserID: userID,
-		NewPostNotificationsEnabled: req.Value,
-	}); err != nil {
+	}, update)
+	if err != nil {
+		if err == service.ErrNotFollowing {
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+	c.JSON(http.StatusOK, preferences)
 }
 
 func (h *Handler) usersGetFollows(c *gin.Context) {
 	user := h.getUser(c)
 
-	var input limitOffsetInput
-	if err := c.ShouldBindJSON(&input); err != nil {
+	var input dto.FollowsPageReq
+	if err := c.ShouldBindQuery(&input); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	subscriptions, err := h.services.User.FindUserFollows(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	page, err := h.services.User.FindUserFollows(c.Request.Context(), user.ID, input.Limit, input.Cursor)
 	if err != nil {
+		if err == service.ErrInvalidCursor {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, subscriptions)
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *Handler) usersUpdate(c *gin.Context) {
 	user := h.getUser(c)
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
+	ifUpdatedAt, err := ifMatchVersion(c, user.UpdatedAt)
+	if err != nil {
+		if err == errIfMatchRequired {
+			c.JSON(http.StatusPreconditionRequired, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	var input dto.UpdateProfileReq
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var fieldErrors dto.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewValidationErrorResponse(fieldErrors))
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	if err := h.services.User.Update(c.Request.Context(), *user, updates); err != nil {
+	updatedAt, err := h.services.User.Update(c.Request.Context(), *user, input, ifUpdatedAt)
+	if err != nil {
+		var fieldErrors dto.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewValidationErrorResponse(fieldErrors))
+			return
+		}
+
+		if err == service.ErrPreconditionFailed {
+			c.JSON(http.StatusPreconditionFailed, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
+	c.Header("ETag", userETag(updatedAt))
+
 	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
 func (h *Handler) usersSetAvatar(c *gin.Context) {
 	user := h.getUser(c)
 
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MAX_IMAGE_UPLOAD_SIZE + (1 << 20))
+
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	if err := h.services.User.SetAvatar(c.Request.Context(), *user, fileHeader); err != nil {
+	var crop dto.AvatarCropReq
+	if err := c.ShouldBind(&crop); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.User.SetAvatar(c.Request.Context(), *user, fileHeader, crop); err != nil {
+		c.JSON(imageUploadErrorStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersRemoveAvatar(c *gin.Context) {
+	user := h.getUser(c)
+
+	if err := h.services.User.RemoveAvatar(c.Request.Context(), *user); err != nil {
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
@@ -173,6 +674,39 @@ func (h *Handler) usersSetAvatar(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
+func (h *Handler) usersSetBanner(c *gin.Context) {
+	user := h.getUser(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MAX_IMAGE_UPLOAD_SIZE + (1 << 20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.User.SetBanner(c.Request.Context(), *user, fileHeader); err != nil {
+		c.JSON(imageUploadErrorStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+// imageUploadErrorStatus tells the client errors of an uploaded image from the internal ones.
+func imageUploadErrorStatus(err error) int {
+	switch err {
+	case service.ErrImageTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case service.ErrFileMustBeImage, service.ErrFileMustHaveValidExtension:
+		return http.StatusUnsupportedMediaType
+	case service.ErrImageDimensionsTooLarge, service.ErrInvalidCropRect, service.ErrInvalidBannerAspectRatio:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *Handler) usersAddSocialLink(c *gin.Context) {
 	user := h.getUser(c)
 
@@ -182,7 +716,74 @@ func (h *Handler) usersAddSocialLink(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.User.AddSocialLink(c.Request.Context(), *user, input.URL); err != nil {
+	if err := h.services.User.AddSocialLink(c.Request.Context(), *user, input); err != nil {
+		var fieldErrors dto.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewValidationErrorResponse(fieldErrors))
+			return
+		}
+
+		switch err {
+		case service.ErrLinkHasInvalidType, service.ErrLinkIsNotValidURL:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrSocialLinkAlreadySet, service.ErrMaxSocialLinksAchieved:
+			c.JSON(http.StatusConflict, dto.NewBasicResponse(false, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersUpdateSocialLink(c *gin.Context) {
+	user := h.getUser(c)
+
+	platform := strings.TrimSpace(c.Param("platform"))
+
+	var input dto.UpdateSocialLinkReq
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.User.UpdateSocialLink(c.Request.Context(), *user, platform, input); err != nil {
+		var fieldErrors dto.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusUnprocessableEntity, dto.NewValidationErrorResponse(fieldErrors))
+			return
+		}
+
+		switch err {
+		case service.ErrSocialLinkNotFound:
+			c.JSON(http.StatusNotFound, dto.NewBasicResponse(false, err.Error()))
+		case service.ErrSocialLinkURLCannotBeNull, service.ErrSocialLinkPlatformMismatch, service.ErrLinkHasInvalidType, service.ErrLinkIsNotValidURL:
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) usersReorderSocialLinks(c *gin.Context) {
+	user := h.getUser(c)
+
+	var input dto.ReorderSocialLinksReq
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.User.ReorderSocialLinks(c.Request.Context(), *user, input.Platforms); err != nil {
+		if err == service.ErrInvalidSocialLinksOrder {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeUserService answers the methods a test sets, the others panic.
+type fakeUserService struct {
+	service.User
+	update                        func(ifUpdatedAt *time.Time) (time.Time, error)
+	updateNotificationPreferences func(update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, error)
+}
+
+func (s *fakeUserService) Update(ctx context.Context, user model.FullUser, req dto.UpdateProfileReq, ifUpdatedAt *time.Time) (time.Time, error) {
+	return s.update(ifUpdatedAt)
+}
+
+func (s *fakeUserService) UpdateNotificationPreferences(ctx context.Context, follower model.Follower, update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, error) {
+	return s.updateNotificationPreferences(update)
+}
+
+func TestUsersUpdateNotifications(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		notFollows bool
+		want       int
+		wantUpdate *model.NotificationPreferencesUpdate
+	}{
+		{
+			name: "level",
+			body: `{"level": "highlighted"}`,
+			want: http.StatusOK,
+			wantUpdate: &model.NotificationPreferencesUpdate{Level: ptr("highlighted")},
+		},
+		{
+			name: "all of them",
+			body: `{"level": "none", "channels": ["email", "push"], "digest": "weekly"}`,
+			want: http.StatusOK,
+			wantUpdate: &model.NotificationPreferencesUpdate{Level: ptr("none"), Channels: []string{"email", "push"}, Digest: ptr("weekly")},
+		},
+		{
+			name: "nothing",
+			body: `{}`,
+			want: http.StatusOK,
+			wantUpdate: &model.NotificationPreferencesUpdate{},
+		},
+		{name: "unknown level", body: `{"level": "some"}`, want: http.StatusBadRequest},
+		{name: "unknown channel", body: `{"channels": ["sms"]}`, want: http.StatusBadRequest},
+		{name: "empty channels", body: `{"channels": []}`, want: http.StatusBadRequest},
+		{name: "repeated channel", body: `{"channels": ["email", "email"]}`, want: http.StatusBadRequest},
+		{name: "unknown digest", body: `{"digest": "monthly"}`, want: http.StatusBadRequest},
+		{name: "not json", body: `level=all`, want: http.StatusBadRequest},
+		{name: "not following", body: `{"level": "all"}`, notFollows: true, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUpdate *model.NotificationPreferencesUpdate
+			h := New(&service.Service{User: &fakeUserService{updateNotificationPreferences: func(update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, error) {
+				gotUpdate = &update
+				if tt.notFollows {
+					return nil, service.ErrNotFollowing
+				}
+				return &model.NotificationPreferences{}, nil
+			}}})
+
+			c, recorder := newTestContext(nil)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "userID", Value: uuid.NewString()}}
+			c.Set("user", model.FullUser{ID: uuid.New()})
+
+			h.usersUpdateNotifications(c)
+
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body.String())
+			}
+			if tt.wantUpdate != nil && !reflect.DeepEqual(gotUpdate, tt.wantUpdate) {
+				t.Errorf("update = %+v, want %+v", gotUpdate, tt.wantUpdate)
+			}
+			if tt.want == http.StatusBadRequest && gotUpdate != nil {
+				t.Errorf("update = %+v, want none", gotUpdate)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
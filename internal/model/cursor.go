@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// FollowCursor points at the last follow of a page, follows are listed newest first
+// and ties are broken by the id of the listed user.
+type FollowCursor struct {
+	FollowedAt time.Time
+	ID         uuid.UUID
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c FollowCursor) Encode() string {
+	raw := strconv.FormatInt(c.FollowedAt.UnixMicro(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeFollowCursor(cursor string) (*FollowCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	followedAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &FollowCursor{
+		FollowedAt: time.UnixMicro(followedAt).UTC(),
+		ID: parsedID,
+	}, nil
+}
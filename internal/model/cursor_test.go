@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFollowCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("6f1c2a9e-4b7d-4e38-9a51-0c3d8e2f7b14")
+
+	tests := []struct {
+		name   string
+		cursor FollowCursor
+	}{
+		{name: "utc", cursor: FollowCursor{FollowedAt: time.Date(2026, 10, 19, 8, 0, 0, 123456000, time.UTC), ID: id}},
+		{name: "other zone", cursor: FollowCursor{FollowedAt: time.Date(2026, 10, 19, 11, 0, 0, 0, time.FixedZone("MSK", 3 * 60 * 60)), ID: id}},
+		{name: "before the epoch", cursor: FollowCursor{FollowedAt: time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC), ID: uuid.Nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeFollowCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeFollowCursor() error = %v", err)
+			}
+			if !got.FollowedAt.Equal(tt.cursor.FollowedAt) || got.ID != tt.cursor.ID {
+				t.Errorf("DecodeFollowCursor() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestFollowCursorTruncatesToMicroseconds(t *testing.T) {
+	followedAt := time.Date(2026, 10, 19, 8, 0, 0, 123456789, time.UTC)
+
+	got, err := DecodeFollowCursor(FollowCursor{FollowedAt: followedAt}.Encode())
+	if err != nil {
+		t.Fatalf("DecodeFollowCursor() error = %v", err)
+	}
+	if want := followedAt.Truncate(time.Microsecond); !got.FollowedAt.Equal(want) {
+		t.Errorf("FollowedAt = %v, want %v", got.FollowedAt, want)
+	}
+}
+
+func TestDecodeFollowCursorInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "empty", cursor: ""},
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("1:6f1c2a9e-4b7d-4e38-9a51-0c3d8e2f7b14"))},
+		{name: "no separator", cursor: encode("1760860800000000")},
+		{name: "invalid time", cursor: encode("yesterday:6f1c2a9e-4b7d-4e38-9a51-0c3d8e2f7b14")},
+		{name: "invalid id", cursor: encode("1760860800000000:alice")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := DecodeFollowCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeFollowCursor(%q) = %v, %v, want ErrInvalidCursor", tt.cursor, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FollowCountDrift is a user whose stored counts differed from the followers table.
+type FollowCountDrift struct {
+	UserID          uuid.UUID `json:"user_id"`
+	Username        string    `json:"username"`
+	Followers       int64     `json:"followers"`
+	ActualFollowers int64     `json:"actual_followers"`
+	Follows         int64     `json:"follows"`
+	ActualFollows   int64     `json:"actual_follows"`
+}
+
+// FollowCountsReconciliation is a report of a follow counts reconciliation run,
+// FinishedAt is nil while it is running.
+type FollowCountsReconciliation struct {
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at"`
+	Scanned    int64               `json:"scanned"`
+	Fixed      int64               `json:"fixed"`
+	Drifts     []*FollowCountDrift `json:"drifts"`
+	Error      *string             `json:"error"`
+}
+
+// FollowCountDeltas are changes of a user's counts that aren't in postgres yet.
+type FollowCountDeltas struct {
+	Followers int64
+	Follows   int64
+}
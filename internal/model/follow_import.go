@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FOLLOW_IMPORT_STATUS_PENDING = "pending"
+	FOLLOW_IMPORT_STATUS_RUNNING = "running"
+	FOLLOW_IMPORT_STATUS_DONE = "done"
+	// The instance running the import stopped before it was done
+	FOLLOW_IMPORT_STATUS_FAILED = "failed"
+)
+
+const (
+	FOLLOW_IMPORT_RESULT_FOLLOWED = "followed"
+	FOLLOW_IMPORT_RESULT_REQUESTED = "requested"
+	FOLLOW_IMPORT_RESULT_ALREADY_FOLLOWING = "already_following"
+	FOLLOW_IMPORT_RESULT_NOT_FOUND = "not_found"
+	FOLLOW_IMPORT_RESULT_BLOCKED = "blocked"
+	FOLLOW_IMPORT_RESULT_YOURSELF = "yourself"
+	FOLLOW_IMPORT_RESULT_FAILED = "failed"
+)
+
+// FollowImport is an import of follows from a CSV of usernames, Results are in row order.
+type FollowImport struct {
+	ID         uuid.UUID             `json:"id"`
+	UserID     uuid.UUID             `json:"user_id"`
+	Status     string                `json:"status"`
+	Total      int                   `json:"total"`
+	Processed  int                   `json:"processed"`
+	Results    []*FollowImportResult `json:"results"`
+	CreatedAt  time.Time             `json:"created_at"`
+	FinishedAt *time.Time            `json:"finished_at"`
+}
+
+type FollowImportResult struct {
+	Row      int    `json:"row"`
+	Username string `json:"username"`
+	Result   string `json:"result"`
+}
@@ -1,17 +1,57 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Follower struct {
-	UserID                      uuid.UUID `json:"user_id"`
-	FollowerID                  uuid.UUID `json:"follower_id"`
-	NewPostNotificationsEnabled bool      `json:"new_post_notifications_enabled"`
+	UserID     uuid.UUID `json:"user_id"`
+	FollowerID uuid.UUID `json:"follower_id"`
 }
 
 type FullFollower struct {
-	ID          uuid.UUID `json:"id"`
-	Username    string    `json:"username"`
-	DisplayName *string   `json:"display_name"`
-	AvatarHash  *string   `json:"avatar_hash"`
-	Bio         *string   `json:"bio"`
+	ID            uuid.UUID  `json:"id"`
+	Username      string     `json:"username"`
+	DisplayName   *string    `json:"display_name"`
+	AvatarHash    *string    `json:"avatar_hash"`
+	Bio           *string    `json:"bio"`
+	FollowedAt    *time.Time `json:"followed_at,omitempty"`
+	// Set only in lists of another user's follows
+	ViewerFollows *bool      `json:"viewer_follows,omitempty"`
+	FollowsViewer *bool      `json:"follows_viewer,omitempty"`
+}
+
+// Cursor points at the follow in a followers or follows list.
+func (f *FullFollower) Cursor() FollowCursor {
+	cursor := FollowCursor{ID: f.ID}
+	if f.FollowedAt != nil {
+		cursor.FollowedAt = *f.FollowedAt
+	}
+	return cursor
+}
+
+type FullFollowRequest struct {
+	FullFollower
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// FollowState is whether the viewer and another user follow each other.
+type FollowState struct {
+	ViewerFollows bool
+	FollowsViewer bool
+}
+
+// Relationship is how a viewer relates to another user.
+type Relationship struct {
+	IsFollowing     bool                     `json:"is_following"`
+	IsFollowedBy    bool                     `json:"is_followed_by"`
+	FollowRequested bool                     `json:"follow_requested"`
+	// Set only while following
+	Notifications   *NotificationPreferences `json:"notifications"`
+	IsBlocking      bool                     `json:"is_blocking"`
+	// Users that blocked the viewer look as if they didn't exist, so it's never exposed
+	IsBlockedBy     bool                     `json:"-"`
+	IsMuted         bool                     `json:"is_muted"`
 }
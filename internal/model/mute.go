@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mute hides the muted user's posts from the user's feed, nil ExpiresAt means forever.
+type Mute struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	MutedID   uuid.UUID  `json:"muted_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type FullMute struct {
+	FullFollower
+	MutedAt   time.Time  `json:"muted_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
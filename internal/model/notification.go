@@ -0,0 +1,43 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	NOTIFICATION_LEVEL_ALL = "all"
+	NOTIFICATION_LEVEL_HIGHLIGHTED = "highlighted"
+	NOTIFICATION_LEVEL_NONE = "none"
+)
+
+const (
+	NOTIFICATION_CHANNEL_EMAIL = "email"
+	NOTIFICATION_CHANNEL_IN_APP = "in_app"
+	NOTIFICATION_CHANNEL_PUSH = "push"
+)
+
+const (
+	NOTIFICATION_DIGEST_IMMEDIATE = "immediate"
+	NOTIFICATION_DIGEST_DAILY = "daily"
+	NOTIFICATION_DIGEST_WEEKLY = "weekly"
+)
+
+// NotificationPreferences are how a follower is notified about the posts of a user they follow.
+// A new follow is notified about all posts in-app, immediately.
+type NotificationPreferences struct {
+	Level    string   `json:"level"`
+	Channels []string `json:"channels"`
+	Digest   string   `json:"digest"`
+}
+
+// NotificationPreferencesUpdate changes only the preferences that are set.
+type NotificationPreferencesUpdate struct {
+	Level    *string
+	Channels []string
+	Digest   *string
+}
+
+// NotifiedFollower is a follower to notify about a new post and how.
+type NotifiedFollower struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	Channels   []string  `json:"channels"`
+	Digest     string    `json:"digest"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	REPORT_CATEGORY_SPAM = "spam"
+	REPORT_CATEGORY_HARASSMENT = "harassment"
+	REPORT_CATEGORY_HATE_SPEECH = "hate_speech"
+	REPORT_CATEGORY_IMPERSONATION = "impersonation"
+	REPORT_CATEGORY_INAPPROPRIATE_CONTENT = "inappropriate_content"
+	REPORT_CATEGORY_OTHER = "other"
+)
+
+const (
+	REPORT_STATUS_OPEN = "open"
+	REPORT_STATUS_TRIAGED = "triaged"
+	REPORT_STATUS_RESOLVED = "resolved"
+	REPORT_STATUS_DISMISSED = "dismissed"
+)
+
+// Report is deduplicated per reporter and target, reporting the same user
+// again updates the report and reopens it if it was closed.
+type Report struct {
+	ID          uuid.UUID  `json:"id"`
+	ReporterID  uuid.UUID  `json:"reporter_id"`
+	TargetID    uuid.UUID  `json:"target_id"`
+	Category    string     `json:"category"`
+	Text        *string    `json:"text"`
+	Status      string     `json:"status"`
+	ModeratorID *uuid.UUID `json:"moderator_id"`
+	Note        *string    `json:"note"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type FullReport struct {
+	Report
+	Reporter          FullFollower `json:"reporter"`
+	Target            FullFollower `json:"target"`
+	TargetOpenReports int64        `json:"target_open_reports"`
+}
+
+type ReportsFilter struct {
+	Status   *string
+	Category *string
+	TargetID *uuid.UUID
+}
@@ -1,9 +1,28 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type SocialLink struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Platform string    `json:"platform"`
-	URL      string    `json:"url"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Platform    string     `json:"platform"`
+	URL         string     `json:"url"`
+	DisplayName string     `json:"display_name"`
+	Icon        string     `json:"icon"`
+	Handle      string     `json:"handle"`
+	Verified    bool       `json:"verified"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	Position    int        `json:"position"`
+	Label       *string    `json:"label"`
+}
+
+// SocialLinkToVerify is a social link with the username its rel="me" backlink must point to.
+// FailedChecks counts the checks in a row whose page couldn't be fetched.
+type SocialLinkToVerify struct {
+	SocialLink
+	Username     string `json:"username"`
+	FailedChecks int    `json:"failed_checks"`
 }
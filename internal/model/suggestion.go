@@ -0,0 +1,14 @@
+package model
+
+const (
+	SUGGESTION_REASON_FOLLOWED_BY_FOLLOWS = "followed_by_follows"
+	SUGGESTION_REASON_POPULAR = "popular"
+	SUGGESTION_REASON_RECENTLY_ACTIVE = "recently_active"
+)
+
+// Suggestion is a user suggested to follow, Reason is the strongest reason of the suggestion.
+type Suggestion struct {
+	FullFollower
+	Followers int64  `json:"followers"`
+	Reason    string `json:"reason"`
+}
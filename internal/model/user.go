@@ -6,6 +6,11 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ROLE_USER = "user"
+	ROLE_ADMIN = "admin"
+)
+
 type TempUserData struct {
 	Email        string `json:"email"`
 	Username     string `json:"username"`
@@ -20,6 +25,7 @@ type User struct {
 	DisplayName     *string   `json:"display_name"`
 	AvatarURL       *string   `json:"avatar_url"`
 	Bio             *string   `json:"bio"`
+	IsPrivate       bool      `json:"is_private"`
 	Role            string    `json:"role"`
 	Followers       int64     `json:"followers"`
 	CreatedAt       time.Time `json:"created_at"`
@@ -27,17 +33,24 @@ type User struct {
 }
 
 type FullUser struct {
-	ID                          uuid.UUID     `json:"id"`
-	Email                       string        `json:"email"`
-	Username                    string        `json:"username"`
-	DisplayName                 *string       `json:"display_name"`
-	AvatarURL                   *string       `json:"avatar_url"`
-	Bio                         *string       `json:"bio"`
-	Role                        string        `json:"role"`
-	Followers                   int64         `json:"followers"`
-	CreatedAt                   time.Time     `json:"created_at"`
-	UpdatedAt                   time.Time     `json:"updated_at"`
-	SocialLinks                 []*SocialLink `json:"social_links"`
-	IsFollowing                 bool          `json:"is_following"`
-	NewPostNotificationsEnabled bool          `json:"new_post_notifications_enabled"`
+	ID              uuid.UUID                `json:"id"`
+	Email           string                   `json:"email"`
+	Username        string                   `json:"username"`
+	DisplayName     *string                  `json:"display_name"`
+	AvatarURL       *string                  `json:"avatar_url"`
+	AvatarURLs      map[string]string        `json:"avatar_urls"`
+	BannerURL       *string                  `json:"banner_url"`
+	Bio             *string                  `json:"bio"`
+	IsPrivate       bool                     `json:"is_private"`
+	Role            string                   `json:"role"`
+	Followers       int64                    `json:"followers"`
+	Follows         int64                    `json:"follows"`
+	SuspendedUntil  *time.Time               `json:"suspended_until"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+	SocialLinks     []*SocialLink            `json:"social_links"`
+	IsFollowing     bool                     `json:"is_following"`
+	FollowRequested bool                     `json:"follow_requested"`
+	Notifications   *NotificationPreferences `json:"notifications"`
+	IsBlocking      bool                     `json:"is_blocking"`
 }
@@ -3,4 +3,9 @@ package rabbitmq
 const (
 	USERS_CREATED_EXCHANGE = "users.created"
 	USERS_UPDATE_EXCHANGE = "users.update"
+	USERS_BLOCKED_EXCHANGE = "users.blocked"
+	USERS_UNBLOCKED_EXCHANGE = "users.unblocked"
+	USERS_MUTED_EXCHANGE = "users.muted"
+	USERS_UNMUTED_EXCHANGE = "users.unmuted"
+	FOLLOWS_EXCHANGE = "follows" // topic, routing keys are "follow.<event type>"
 )
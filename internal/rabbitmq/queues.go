@@ -3,7 +3,6 @@ package rabbitmq
 const (
 	REGISTRATION_CODE_MAIL_QUEUE = "notifications.registration_code"
 	SIGNIN_CODE_MAIL_QUEUE = "notifications.signin_code"
-	FOLLOWS_QUEUE = "follows"
-	FOLLOWERS_NEW_POST_NOTIFICATIONS_ENABLED_UPDATES_QUEUE = "followers-new-post-notifications-enabled-updates"
 	USER_FORGOT_PASSWORD_QUEUE = "user-forgot-password"
+	MODERATION_REPORTS_QUEUE = "notifications.moderation_report"
 )
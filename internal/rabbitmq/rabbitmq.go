@@ -75,6 +75,39 @@ func (mq *MQConn) PublishExchange(exchange string, body []byte) error {
 	)
 }
 
+// PublishTopic declares the durable topic exchange and publishes body with routingKey.
+func (mq *MQConn) PublishTopic(exchange string, routingKey string, body []byte) error {
+	ch, err := mq.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(
+		exchange,
+		amqp.ExchangeTopic,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	return ch.Publish(
+		exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType: "application/json",
+			Body: body,
+		},
+	)
+}
+
 func (mq *MQConn) Consume(queue string) (<-chan amqp.Delivery, error) {
 	ch, err := mq.Channel()
 	if err != nil {
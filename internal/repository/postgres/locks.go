@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Advisory lock keys, they are held across all instances of the service.
+const (
+	// Serializes the flushes of the follow count deltas with the reconciliation batches
+	FOLLOW_COUNTS_LOCK = 4607001
+	// Held for the whole nightly computation of the suggestions
+	SUGGESTIONS_LOCK = 4607002
+)
+
+// ErrLocked is returned when another transaction holds the advisory lock.
+var ErrLocked = errors.New("advisory lock is held by another transaction")
+
+// tryAdvisoryXactLock takes the lock until the end of tx, it returns ErrLocked
+// instead of waiting for it.
+func tryAdvisoryXactLock(ctx context.Context, tx pgx.Tx, key int64) error {
+	var locked bool
+	if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&locked); err != nil {
+		return err
+	}
+
+	if !locked {
+		return ErrLocked
+	}
+
+	return nil
+}
+
+// withAdvisoryLock runs fn holding the lock on a connection of its own, for jobs too
+// long for a transaction. It returns ErrLocked instead of waiting for the lock.
+func withAdvisoryLock(ctx context.Context, db *pgxpool.Pool, key int64, fn func(ctx context.Context) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return err
+	}
+
+	if !locked {
+		return ErrLocked
+	}
+
+	defer func() {
+		// A connection still holding the lock must not go back to the pool
+		if _, err := conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	return fn(ctx)
+}
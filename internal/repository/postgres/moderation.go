@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type moderationRepo struct {
+	db *pgxpool.Pool
+}
+
+func newModerationRepo(db *pgxpool.Pool) Moderation {
+	return &moderationRepo{
+		db: db,
+	}
+}
+
+// CreateReport inserts the report or updates the reporter's existing report of
+// the same user, reopening it if it was closed. It returns the status the report
+// had before, nil if it is new.
+func (r *moderationRepo) CreateReport(ctx context.Context, report model.Report) (*model.Report, *string, error) {
+	var previousStatus *string
+	if err := r.db.QueryRow(
+		ctx,
+		`
+		WITH previous AS (
+			SELECT status FROM reports WHERE reporter_id = $2 AND target_id = $3
+		)
+		INSERT INTO reports(id, reporter_id, target_id, category, text, status, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, 'open', $6, $6)
+		ON CONFLICT (reporter_id, target_id) DO UPDATE SET
+			category = EXCLUDED.category,
+			text = EXCLUDED.text,
+			status = CASE WHEN reports.status IN ('resolved', 'dismissed') THEN 'open' ELSE reports.status END,
+			moderator_id = CASE WHEN reports.status IN ('resolved', 'dismissed') THEN NULL ELSE reports.moderator_id END,
+			note = CASE WHEN reports.status IN ('resolved', 'dismissed') THEN NULL ELSE reports.note END,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, status, moderator_id, note, created_at, updated_at, (SELECT status FROM previous)
+		`,
+		uuid.New(),
+		report.ReporterID,
+		report.TargetID,
+		report.Category,
+		report.Text,
+		time.Now(),
+	).Scan(
+		&report.ID,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Note,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+		&previousStatus,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	return &report, previousStatus, nil
+}
+
+// CountOpenReports counts open and triaged reports of the user.
+func (r *moderationRepo) CountOpenReports(ctx context.Context, targetID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.QueryRow(
+		ctx,
+		"SELECT COUNT(*) FROM reports r WHERE r.target_id = $1 AND r.status IN ('open', 'triaged')",
+		targetID,
+	).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// FindReports lists the reports matching the filter, oldest first.
+func (r *moderationRepo) FindReports(ctx context.Context, filter model.ReportsFilter, limit int, offset int) ([]*model.FullReport, error) {
+	maximumLimit(&limit)
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT
+		r.id, r.reporter_id, r.target_id, r.category, r.text, r.status, r.moderator_id, r.note, r.created_at, r.updated_at,
+		ru.username, ru.display_name, ru.avatar_url, ru.bio,
+		tu.username, tu.display_name, tu.avatar_url, tu.bio,
+		(SELECT COUNT(*) FROM reports o WHERE o.target_id = r.target_id AND o.status IN ('open', 'triaged'))
+		FROM reports r
+		JOIN users ru ON r.reporter_id = ru.id
+		JOIN users tu ON r.target_id = tu.id
+		WHERE ($1::text IS NULL OR r.status = $1)
+		AND ($2::text IS NULL OR r.category = $2)
+		AND ($3::uuid IS NULL OR r.target_id = $3)
+		ORDER BY r.created_at
+		LIMIT $4
+		OFFSET $5
+		`,
+		filter.Status,
+		filter.Category,
+		filter.TargetID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*model.FullReport
+	for rows.Next() {
+		var report model.FullReport
+		if err := rows.Scan(
+			&report.ID,
+			&report.ReporterID,
+			&report.TargetID,
+			&report.Category,
+			&report.Text,
+			&report.Status,
+			&report.ModeratorID,
+			&report.Note,
+			&report.CreatedAt,
+			&report.UpdatedAt,
+			&report.Reporter.Username,
+			&report.Reporter.DisplayName,
+			&report.Reporter.AvatarHash,
+			&report.Reporter.Bio,
+			&report.Target.Username,
+			&report.Target.DisplayName,
+			&report.Target.AvatarHash,
+			&report.Target.Bio,
+			&report.TargetOpenReports,
+		); err != nil {
+			return nil, err
+		}
+		report.Reporter.ID = report.ReporterID
+		report.Target.ID = report.TargetID
+
+		reports = append(reports, &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r *moderationRepo) FindReportByID(ctx context.Context, id uuid.UUID) (*model.Report, error) {
+	var report model.Report
+	if err := r.db.QueryRow(
+		ctx,
+		`
+		SELECT r.id, r.reporter_id, r.target_id, r.category, r.text, r.status, r.moderator_id, r.note, r.created_at, r.updated_at
+		FROM reports r
+		WHERE r.id = $1
+		`,
+		id,
+	).Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetID,
+		&report.Category,
+		&report.Text,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Note,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// UpdateReportStatus moves the report to status only if it still has one of
+// the fromStatuses, otherwise pgx.ErrNoRows is returned.
+func (r *moderationRepo) UpdateReportStatus(ctx context.Context, id uuid.UUID, fromStatuses []string, status string, moderatorID uuid.UUID, note *string) error {
+	var updatedID uuid.UUID
+	return r.db.QueryRow(
+		ctx,
+		`
+		UPDATE reports SET status = $1, moderator_id = $2, note = COALESCE($3, note), updated_at = $4
+		WHERE id = $5 AND status = ANY($6)
+		RETURNING id
+		`,
+		status,
+		moderatorID,
+		note,
+		time.Now(),
+		id,
+		fromStatuses,
+	).Scan(&updatedID)
+}
+
+// SuspendUser suspends the user until the given time and resolves all of the
+// user's open and triaged reports, it returns how many reports were resolved.
+func (r *moderationRepo) SuspendUser(ctx context.Context, targetID uuid.UUID, until time.Time, moderatorID uuid.UUID, note *string) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	suspended, err := tx.Exec(ctx, "UPDATE users SET suspended_until = $1, updated_at = now() WHERE id = $2", until, targetID)
+	if err != nil {
+		return 0, err
+	}
+	if suspended.RowsAffected() == 0 {
+		return 0, pgx.ErrNoRows
+	}
+
+	resolved, err := tx.Exec(
+		ctx,
+		`
+		UPDATE reports SET status = 'resolved', moderator_id = $1, note = COALESCE($2, note), updated_at = $3
+		WHERE target_id = $4 AND status IN ('open', 'triaged')
+		`,
+		moderatorID,
+		note,
+		time.Now(),
+		targetID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return resolved.RowsAffected(), tx.Commit(ctx)
+}
+
+func (r *moderationRepo) LiftSuspension(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET suspended_until = NULL, updated_at = now() WHERE id = $1", userID)
+	return err
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/BloggingApp/user-service/internal/model"
 	"github.com/google/uuid"
@@ -13,29 +14,78 @@ type User interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser, error)
 	FindPassword(ctx context.Context, id uuid.UUID) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
-	FindByUsername(ctx context.Context, getterID *uuid.UUID, username string) (*model.FullUser, error)
+	FindByUsername(ctx context.Context, username string) (*model.FullUser, error)
 	FindByEmailOrUsername(ctx context.Context, email string, username string) (*model.User, error)
-	UpdateByID(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
+	UpdateByID(ctx context.Context, id uuid.UUID, updates map[string]interface{}, ifUpdatedAt *time.Time) (time.Time, error)
 	UpdatePasswordHash(ctx context.Context, id uuid.UUID, newPasswordHash string) error
-	SearchByUsername(ctx context.Context, username string, limit int, offset int) ([]*model.FullUser, error)
-	FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error)
-	Follow(ctx context.Context, follower model.Follower) error
-	Unfollow(ctx context.Context, follower model.Follower) error
-	UpdateNewPostNotificationsEnabled(ctx context.Context, follower model.Follower) error
-	FindUserFollows(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error)
+	UpdateLastActiveAt(ctx context.Context, id uuid.UUID) error
+	SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*model.FullUser, error)
+	FindUserFollowers(ctx context.Context, id uuid.UUID, after *model.FollowCursor, limit int) ([]*model.FullFollower, error)
+	Follow(ctx context.Context, follower model.Follower) (bool, error)
+	Unfollow(ctx context.Context, follower model.Follower) (bool, error)
+	UpdateNotificationPreferences(ctx context.Context, follower model.Follower, update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, *model.NotificationPreferences, error)
+	AddFollowCounts(ctx context.Context, take func(ctx context.Context) (map[uuid.UUID]model.FollowCountDeltas, error)) (map[uuid.UUID]string, error)
+	ReconcileFollowCounts(ctx context.Context, after uuid.UUID, limit int, pending func(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.FollowCountDeltas, error)) ([]uuid.UUID, []*model.FollowCountDrift, error)
+	FindRelationship(ctx context.Context, followerID uuid.UUID, userID uuid.UUID) (*model.Relationship, error)
+	FindRelationships(ctx context.Context, followerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.Relationship, error)
+	FindMutualFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int) ([]*model.FullFollower, int64, error)
+	FindFollowersByIDs(ctx context.Context, userID uuid.UUID, followerIDs []uuid.UUID, limit int) ([]*model.FullFollower, error)
+	FindFollowerIDs(ctx context.Context, userID uuid.UUID, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	FindFollowIDs(ctx context.Context, followerID uuid.UUID, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	FindFollowStates(ctx context.Context, viewerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.FollowState, error)
+	CreateFollowRequest(ctx context.Context, follower model.Follower) error
+	FindFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullFollowRequest, error)
+	ApproveFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (bool, error)
+	ApproveAllFollowRequests(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	DeleteFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (bool, error)
+	Block(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) ([]model.Follower, bool, error)
+	Unblock(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) (bool, error)
+	FindBlockerIDs(ctx context.Context, blockedID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	Mute(ctx context.Context, mute model.Mute) error
+	Unmute(ctx context.Context, userID uuid.UUID, mutedID uuid.UUID) (bool, error)
+	FindMutes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullMute, error)
+	FindUserFollows(ctx context.Context, id uuid.UUID, after *model.FollowCursor, limit int) ([]*model.FullFollower, error)
+	StreamNotifiedFollowers(ctx context.Context, userID uuid.UUID, highlighted bool, batchSize int, fn func(followers []*model.NotifiedFollower) error) error
 	ExistsWithID(ctx context.Context, id uuid.UUID) (bool, error)
 	ExistsWithUsername(ctx context.Context, username string) (bool, error)
 	FindUserSocialLinks(ctx context.Context, userID uuid.UUID) ([]*model.SocialLink, error)
 	AddSocialLink(ctx context.Context, link model.SocialLink) error
+	UpdateSocialLink(ctx context.Context, link model.SocialLink) error
+	ReorderSocialLinks(ctx context.Context, userID uuid.UUID, platforms []string) error
 	DeleteSocialLink(ctx context.Context, userID uuid.UUID, platform string) error
+	FindSocialLinksToVerify(ctx context.Context, now time.Time, limit int) ([]*model.SocialLinkToVerify, error)
+	UpdateSocialLinkVerification(ctx context.Context, link model.SocialLink, checkedAt time.Time, nextCheckAt time.Time) error
+	FailSocialLinkCheck(ctx context.Context, link model.SocialLink, nextCheckAt time.Time) error
+}
+
+type Moderation interface {
+	CreateReport(ctx context.Context, report model.Report) (*model.Report, *string, error)
+	CountOpenReports(ctx context.Context, targetID uuid.UUID) (int64, error)
+	FindReports(ctx context.Context, filter model.ReportsFilter, limit int, offset int) ([]*model.FullReport, error)
+	FindReportByID(ctx context.Context, id uuid.UUID) (*model.Report, error)
+	UpdateReportStatus(ctx context.Context, id uuid.UUID, fromStatuses []string, status string, moderatorID uuid.UUID, note *string) error
+	SuspendUser(ctx context.Context, targetID uuid.UUID, until time.Time, moderatorID uuid.UUID, note *string) (int64, error)
+	LiftSuspension(ctx context.Context, userID uuid.UUID) error
+}
+
+type Suggestion interface {
+	ComputeSuggestions(ctx context.Context, userID uuid.UUID, count int, activeSince time.Time) error
+	FindSuggestions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.Suggestion, error)
+	DismissSuggestion(ctx context.Context, userID uuid.UUID, dismissedID uuid.UUID) error
+	FindUserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	WithComputeLock(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type PostgresRepository struct {
 	User
+	Moderation
+	Suggestion
 }
 
 func New(db *pgxpool.Pool) *PostgresRepository {
 	return &PostgresRepository{
 		User: newUserRepo(db),
+		Moderation: newModerationRepo(db),
+		Suggestion: newSuggestionRepo(db),
 	}
 }
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type suggestionRepo struct {
+	db *pgxpool.Pool
+}
+
+func newSuggestionRepo(db *pgxpool.Pool) Suggestion {
+	return &suggestionRepo{
+		db: db,
+	}
+}
+
+// suggestable filters out the candidates in column that userID ($1) must not be
+// suggested: the user itself, suspended users, users the user follows or has
+// requested to follow, blocked in either direction or muted.
+func suggestable(column string) string {
+	return fmt.Sprintf(
+		`
+		%[1]s <> $1
+		AND (u.suspended_until IS NULL OR u.suspended_until <= now())
+		AND NOT EXISTS(SELECT 1 FROM followers f WHERE f.user_id = %[1]s AND f.follower_id = $1)
+		AND NOT EXISTS(SELECT 1 FROM follow_requests fr WHERE fr.user_id = %[1]s AND fr.follower_id = $1)
+		AND NOT EXISTS(SELECT 1 FROM blocks b WHERE (b.user_id = $1 AND b.blocked_id = %[1]s) OR (b.user_id = %[1]s AND b.blocked_id = $1))
+		AND NOT EXISTS(SELECT 1 FROM mutes m WHERE m.user_id = $1 AND m.muted_id = %[1]s AND (m.expires_at IS NULL OR m.expires_at > now()))
+		`,
+		column,
+	)
+}
+
+// undismissed filters out the candidates in column that the user ($1) has dismissed.
+func undismissed(column string) string {
+	return "NOT EXISTS(SELECT 1 FROM suggestion_dismissals d WHERE d.user_id = $1 AND d.dismissed_id = " + column + ")"
+}
+
+// ComputeSuggestions replaces the user's suggestions with the count best scored
+// candidates. Users followed by the user's follows score 3 per such follow,
+// popular users score ln(followers + 1) and users active since activeSince score 1.
+// The popular and recently active users are filtered before they are limited, so
+// that a user following all of the most popular ones still gets count of them.
+func (r *suggestionRepo) ComputeSuggestions(ctx context.Context, userID uuid.UUID, count int, activeSince time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM follow_suggestions WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		INSERT INTO follow_suggestions(user_id, suggested_id, score, reason, computed_at)
+		SELECT $1, c.suggested_id, sum(c.score), (array_agg(c.reason ORDER BY c.score DESC))[1], now()
+		FROM (
+			(
+				SELECT f2.user_id AS suggested_id, 3 * count(*)::float8 AS score, '`+model.SUGGESTION_REASON_FOLLOWED_BY_FOLLOWS+`' AS reason
+				FROM followers f1
+				JOIN followers f2 ON f2.follower_id = f1.user_id
+				WHERE f1.follower_id = $1
+				GROUP BY f2.user_id
+			)
+			UNION ALL
+			(
+				SELECT u.id, ln(u.followers + 1), '`+model.SUGGESTION_REASON_POPULAR+`'
+				FROM users u
+				WHERE `+suggestable("u.id")+`
+				AND `+undismissed("u.id")+`
+				ORDER BY u.followers DESC
+				LIMIT $2
+			)
+			UNION ALL
+			(
+				SELECT u.id, 1, '`+model.SUGGESTION_REASON_RECENTLY_ACTIVE+`'
+				FROM users u
+				WHERE u.last_active_at > $3
+				AND `+suggestable("u.id")+`
+				AND `+undismissed("u.id")+`
+				ORDER BY u.last_active_at DESC
+				LIMIT $2
+			)
+		) c
+		JOIN users u ON u.id = c.suggested_id
+		WHERE `+suggestable("c.suggested_id")+`
+		AND `+undismissed("c.suggested_id")+`
+		GROUP BY c.suggested_id
+		ORDER BY sum(c.score) DESC
+		LIMIT $2
+		`,
+		userID,
+		count,
+		activeSince,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindSuggestions finds the user's precomputed suggestions, best first, leaving out
+// the users that became unsuggestable since they were computed.
+func (r *suggestionRepo) FindSuggestions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.Suggestion, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT u.id, u.username, u.display_name, u.avatar_url, u.bio, u.followers, s.reason
+		FROM follow_suggestions s
+		JOIN users u ON u.id = s.suggested_id
+		WHERE s.user_id = $1
+		AND `+suggestable("s.suggested_id")+`
+		ORDER BY s.score DESC, s.suggested_id
+		LIMIT $2
+		`,
+		userID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []*model.Suggestion
+	for rows.Next() {
+		var suggestion model.Suggestion
+		if err := rows.Scan(
+			&suggestion.ID,
+			&suggestion.Username,
+			&suggestion.DisplayName,
+			&suggestion.AvatarHash,
+			&suggestion.Bio,
+			&suggestion.Followers,
+			&suggestion.Reason,
+		); err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// DismissSuggestion stops suggesting dismissedID to the user.
+func (r *suggestionRepo) DismissSuggestion(ctx context.Context, userID uuid.UUID, dismissedID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO suggestion_dismissals(user_id, dismissed_id, dismissed_at) VALUES($1, $2, now()) ON CONFLICT DO NOTHING",
+		userID,
+		dismissedID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM follow_suggestions WHERE user_id = $1 AND suggested_id = $2", userID, dismissedID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindUserIDs pages through the IDs of users that aren't suspended in ID order.
+func (r *suggestionRepo) FindUserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT u.id FROM users u
+		WHERE u.id > $1 AND (u.suspended_until IS NULL OR u.suspended_until <= now())
+		ORDER BY u.id
+		LIMIT $2
+		`,
+		after,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// WithComputeLock runs fn under SUGGESTIONS_LOCK, so that only one instance computes
+// everyone's suggestions. ErrLocked is returned while another instance holds it.
+func (r *suggestionRepo) WithComputeLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withAdvisoryLock(ctx, r.db, SUGGESTIONS_LOCK, fn)
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -13,6 +14,10 @@ import (
 
 const MAX_LIMIT = 50
 
+// SEARCH_FOLLOWERS_WEIGHT weighs ln(followers + 1) against the 0..1 text relevance of a
+// search result, so a popular user outranks a slightly closer match, not an exact one.
+const SEARCH_FOLLOWERS_WEIGHT = 0.02
+
 type userRepo struct {
 	db *pgxpool.Pool
 }
@@ -26,7 +31,7 @@ func newUserRepo(db *pgxpool.Pool) User {
 func (r *userRepo) Create(ctx context.Context, user model.User) (*model.User, error) {
 	user.ID = uuid.New()
 	user.AvatarURL = nil
-	user.Role = "user"
+	user.Role = model.ROLE_USER
 	user.Followers = 0
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
@@ -48,10 +53,11 @@ func (r *userRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser,
 		ctx,
 		`
 		SELECT
-		u.id, u.email, u.username, u.display_name, u.avatar_url, u.bio, u.role, u.followers, u.created_at, u.updated_at, sl.platform, sl.url
+		u.id, u.email, u.username, u.display_name, u.avatar_url, u.avatar_urls, u.banner_url, u.bio, u.is_private, u.role, u.followers, u.follows, u.suspended_until, u.created_at, u.updated_at, sl.platform, sl.url, sl.verified, sl.verified_at, sl.position, sl.label
 		FROM users u
 		LEFT JOIN social_links sl ON u.id = sl.user_id
 		WHERE u.id = $1
+		ORDER BY sl.position
 		`,
 		id,
 	)
@@ -68,13 +74,22 @@ func (r *userRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser,
 			userUsername string
 			userDisplayName *string
 			userAvatarURL *string
+			userAvatarURLs map[string]string
+			userBannerURL *string
 			userBio *string
+			userIsPrivate bool
 			userRole string
 			userFollowers int64
+			userFollows int64
+			userSuspendedUntil *time.Time
 			userCreatedAt time.Time
 			userUpdatedAt time.Time
 			socialLinkPlatform *string
 			socialLinkUrl *string
+			socialLinkVerified *bool
+			socialLinkVerifiedAt *time.Time
+			socialLinkPosition *int
+			socialLinkLabel *string
 		)
 		if err := rows.Scan(
 			&userID,
@@ -82,13 +97,22 @@ func (r *userRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser,
 			&userUsername,
 			&userDisplayName,
 			&userAvatarURL,
+			&userAvatarURLs,
+			&userBannerURL,
 			&userBio,
+			&userIsPrivate,
 			&userRole,
 			&userFollowers,
+			&userFollows,
+			&userSuspendedUntil,
 			&userCreatedAt,
 			&userUpdatedAt,
 			&socialLinkPlatform,
 			&socialLinkUrl,
+			&socialLinkVerified,
+			&socialLinkVerifiedAt,
+			&socialLinkPosition,
+			&socialLinkLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -101,9 +125,14 @@ func (r *userRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser,
                 Username: userUsername,
                 DisplayName: userDisplayName,
                 AvatarURL: userAvatarURL,
+                AvatarURLs: userAvatarURLs,
+                BannerURL: userBannerURL,
                 Bio: userBio,
+                IsPrivate: userIsPrivate,
                 Role: userRole,
 				Followers: userFollowers,
+				Follows: userFollows,
+                SuspendedUntil: userSuspendedUntil,
                 CreatedAt: userCreatedAt,
                 UpdatedAt: userUpdatedAt,
                 SocialLinks: []*model.SocialLink{},
@@ -116,6 +145,10 @@ func (r *userRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser,
 				UserID: userID,
 				Platform: *socialLinkPlatform,
 				URL: *socialLinkUrl,
+				Verified: socialLinkVerified != nil && *socialLinkVerified,
+				VerifiedAt: socialLinkVerifiedAt,
+				Position: *socialLinkPosition,
+				Label: socialLinkLabel,
 			})
 		}
 	}
@@ -170,22 +203,17 @@ func (r *userRepo) FindByEmail(ctx context.Context, email string) (*model.User,
 	return &user, nil
 }
 
-func (r *userRepo) FindByUsername(ctx context.Context, getterID *uuid.UUID, username string) (*model.FullUser, error) {
-	if getterID == nil {
-		getterID = &uuid.UUID{}
-	}
-
+func (r *userRepo) FindByUsername(ctx context.Context, username string) (*model.FullUser, error) {
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		u.id, u.email, u.username, u.display_name, u.avatar_url, u.bio, u.role, u.followers, u.created_at, u.updated_at, sl.platform, sl.url, f.new_post_notifications_enabled
+		u.id, u.email, u.username, u.display_name, u.avatar_url, u.avatar_urls, u.banner_url, u.bio, u.is_private, u.role, u.followers, u.follows, u.suspended_until, u.created_at, u.updated_at, sl.platform, sl.url, sl.verified, sl.verified_at, sl.position, sl.label
 		FROM users u
 		LEFT JOIN social_links sl ON u.id = sl.user_id
-		LEFT JOIN followers f ON f.user_id = u.id AND f.follower_id = $1
-		WHERE u.username = $2
+		WHERE u.username = $1
+		ORDER BY sl.position
 		`,
-		*getterID,
 		username,
 	)
 	if err != nil {
@@ -201,14 +229,22 @@ func (r *userRepo) FindByUsername(ctx context.Context, getterID *uuid.UUID, user
 			userUsername string
 			userDisplayName *string
 			userAvatarURL *string
+			userAvatarURLs map[string]string
+			userBannerURL *string
 			userBio *string
+			userIsPrivate bool
 			userRole string
 			userFollowers int64
+			userFollows int64
+			userSuspendedUntil *time.Time
 			userCreatedAt time.Time
 			userUpdatedAt time.Time
 			socialLinkPlatform *string
 			socialLinkUrl *string
-			newPostNotificationsEnabled *bool
+			socialLinkVerified *bool
+			socialLinkVerifiedAt *time.Time
+			socialLinkPosition *int
+			socialLinkLabel *string
 		)
 		if err := rows.Scan(
 			&userID,
@@ -216,14 +252,22 @@ func (r *userRepo) FindByUsername(ctx context.Context, getterID *uuid.UUID, user
 			&userUsername,
 			&userDisplayName,
 			&userAvatarURL,
+			&userAvatarURLs,
+			&userBannerURL,
 			&userBio,
+			&userIsPrivate,
 			&userRole,
 			&userFollowers,
+			&userFollows,
+			&userSuspendedUntil,
 			&userCreatedAt,
 			&userUpdatedAt,
 			&socialLinkPlatform,
 			&socialLinkUrl,
-			&newPostNotificationsEnabled,
+			&socialLinkVerified,
+			&socialLinkVerifiedAt,
+			&socialLinkPosition,
+			&socialLinkLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -236,9 +280,14 @@ func (r *userRepo) FindByUsername(ctx context.Context, getterID *uuid.UUID, user
                 Username: userUsername,
                 DisplayName: userDisplayName,
                 AvatarURL: userAvatarURL,
+                AvatarURLs: userAvatarURLs,
+                BannerURL: userBannerURL,
                 Bio: userBio,
+                IsPrivate: userIsPrivate,
                 Role: userRole,
 				Followers: userFollowers,
+				Follows: userFollows,
+                SuspendedUntil: userSuspendedUntil,
                 CreatedAt: userCreatedAt,
                 UpdatedAt: userUpdatedAt,
                 SocialLinks: []*model.SocialLink{},
@@ -251,15 +300,12 @@ func (r *userRepo) FindByUsername(ctx context.Context, getterID *uuid.UUID, user
 				UserID: userID,
 				Platform: *socialLinkPlatform,
 				URL: *socialLinkUrl,
+				Verified: socialLinkVerified != nil && *socialLinkVerified,
+				VerifiedAt: socialLinkVerifiedAt,
+				Position: *socialLinkPosition,
+				Label: socialLinkLabel,
 			})
 		}
-
-		if newPostNotificationsEnabled != nil {
-			user.IsFollowing = true
-			user.NewPostNotificationsEnabled = *newPostNotificationsEnabled
-		} else {
-			user.IsFollowing = false
-		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -303,8 +349,11 @@ func (r *userRepo) FindByEmailOrUsername(ctx context.Context, email string, user
 	return &user, nil
 }
 
-func (r *userRepo) UpdateByID(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
-	allowedFields := []string{"username", "display_name", "bio", "avatar_url", "followers"}
+// UpdateByID bumps updated_at together with the updates and returns its new value.
+// When ifUpdatedAt is set the row is only updated if it still has that updated_at,
+// otherwise pgx.ErrNoRows is returned.
+func (r *userRepo) UpdateByID(ctx context.Context, id uuid.UUID, updates map[string]interface{}, ifUpdatedAt *time.Time) (time.Time, error) {
+	allowedFields := []string{"username", "display_name", "bio", "avatar_url", "avatar_urls", "banner_url", "is_private"}
 	allowedFieldsSet := make(map[string]struct{}, len(allowedFields))
 	for _, field := range allowedFields {
 		allowedFieldsSet[field] = struct{}{}
@@ -317,7 +366,7 @@ func (r *userRepo) UpdateByID(ctx context.Context, id uuid.UUID, updates map[str
 	}
 
 	if len(updates) == 0 {
-		return nil
+		return time.Time{}, nil
 	}
 
 	query := "UPDATE users SET "
@@ -330,11 +379,27 @@ func (r *userRepo) UpdateByID(ctx context.Context, id uuid.UUID, updates map[str
 		i++
 	}
 
-	query = query[:len(query)-2] + " WHERE id = $" + strconv.Itoa(i)
+	query += "updated_at = $" + strconv.Itoa(i)
+	args = append(args, time.Now())
+	i++
+
+	query += " WHERE id = $" + strconv.Itoa(i)
 	args = append(args, id)
+	i++
 
-	_, err := r.db.Exec(ctx, query, args...)
-	return err
+	if ifUpdatedAt != nil {
+		query += " AND updated_at = $" + strconv.Itoa(i)
+		args = append(args, *ifUpdatedAt)
+	}
+
+	query += " RETURNING updated_at"
+
+	var updatedAt time.Time
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&updatedAt); err != nil {
+		return time.Time{}, err
+	}
+
+	return updatedAt, nil
 }
 
 func (r *userRepo) UpdatePasswordHash(ctx context.Context, id uuid.UUID, newPasswordHash string) error {
@@ -342,21 +407,41 @@ func (r *userRepo) UpdatePasswordHash(ctx context.Context, id uuid.UUID, newPass
 	return err
 }
 
-func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit int, offset int) ([]*model.FullUser, error) {
+func (r *userRepo) UpdateLastActiveAt(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE users SET last_active_at = now() WHERE id = $1", id)
+	return err
+}
+
+// SearchUsers finds the users whose username or display name looks like the query,
+// most relevant first. The relevance is the pg_trgm similarity of the closest of the
+// two, blended with the follower count, suspended users are left out. The matches
+// are found through the trigram indexes of the users_*_trgm_idx migrations.
+func (r *userRepo) SearchUsers(ctx context.Context, query string, limit int, offset int) ([]*model.FullUser, error) {
 	maximumLimit(&limit)
 
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		u.id, u.email, u.username, u.display_name, u.avatar_url, u.bio, u.role, u.followers, u.created_at, u.updated_at, sl.platform, sl.url
-		FROM users u
+		u.id, u.email, u.username, u.display_name, u.avatar_url, u.avatar_urls, u.banner_url, u.bio, u.is_private, u.role, u.followers, u.follows, u.suspended_until, u.created_at, u.updated_at, sl.platform, sl.url, sl.verified, sl.verified_at, sl.position, sl.label
+		FROM (
+			SELECT u.id, greatest(
+				(similarity(u.username, $1) + word_similarity($1, u.username)) / 2,
+				(similarity(coalesce(u.display_name, ''), $1) + word_similarity($1, coalesce(u.display_name, ''))) / 2
+			) + $2 * ln(u.followers + 1) AS score
+			FROM users u
+			WHERE ($1 <% u.username OR $1 <% u.display_name)
+			AND (u.suspended_until IS NULL OR u.suspended_until <= now())
+			ORDER BY score DESC, u.id
+			LIMIT $3
+			OFFSET $4
+		) m
+		JOIN users u ON m.id = u.id
 		LEFT JOIN social_links sl ON u.id = sl.user_id
-		WHERE u.username LIKE %$1%
-		LIMIT $2
-		OFFSET $3
+		ORDER BY m.score DESC, u.id, sl.position
 		`,
-		username,
+		query,
+		SEARCH_FOLLOWERS_WEIGHT,
 		limit,
 		offset,
 	)
@@ -365,6 +450,7 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
 	}
 	defer rows.Close()
 
+	var users []*model.FullUser
 	userMap := make(map[uuid.UUID]*model.FullUser)
 	for rows.Next() {
 		var (
@@ -373,13 +459,22 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
 			userUsername string
 			userDisplayName *string
 			userAvatarURL *string
+			userAvatarURLs map[string]string
+			userBannerURL *string
 			userBio *string
+			userIsPrivate bool
 			userRole string
 			userFollowers int64
+			userFollows int64
+			userSuspendedUntil *time.Time
 			userCreatedAt time.Time
 			userUpdatedAt time.Time
 			socialLinkPlatform *string
 			socialLinkUrl *string
+			socialLinkVerified *bool
+			socialLinkVerifiedAt *time.Time
+			socialLinkPosition *int
+			socialLinkLabel *string
 		)
 		if err := rows.Scan(
 			&userID,
@@ -387,13 +482,22 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
 			&userUsername,
 			&userDisplayName,
 			&userAvatarURL,
+			&userAvatarURLs,
+			&userBannerURL,
 			&userBio,
+			&userIsPrivate,
 			&userRole,
 			&userFollowers,
+			&userFollows,
+			&userSuspendedUntil,
 			&userCreatedAt,
 			&userUpdatedAt,
 			&socialLinkPlatform,
 			&socialLinkUrl,
+			&socialLinkVerified,
+			&socialLinkVerifiedAt,
+			&socialLinkPosition,
+			&socialLinkLabel,
 		); err != nil {
 			return nil, err
 		}
@@ -406,14 +510,20 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
                 Username: userUsername,
                 DisplayName: userDisplayName,
                 AvatarURL: userAvatarURL,
+                AvatarURLs: userAvatarURLs,
+                BannerURL: userBannerURL,
                 Bio: userBio,
+                IsPrivate: userIsPrivate,
                 Role: userRole,
 				Followers: userFollowers,
+				Follows: userFollows,
+                SuspendedUntil: userSuspendedUntil,
                 CreatedAt: userCreatedAt,
                 UpdatedAt: userUpdatedAt,
                 SocialLinks: []*model.SocialLink{},
 			}
 			userMap[userID] = user
+			users = append(users, user)
 		}
 
 		if socialLinkPlatform != nil && socialLinkUrl != nil {
@@ -421,6 +531,10 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
 				UserID: user.ID,
 				Platform: *socialLinkPlatform,
 				URL: *socialLinkUrl,
+				Verified: socialLinkVerified != nil && *socialLinkVerified,
+				VerifiedAt: socialLinkVerifiedAt,
+				Position: *socialLinkPosition,
+				Label: socialLinkLabel,
 			})
 		}
 	}
@@ -429,11 +543,6 @@ func (r *userRepo) SearchByUsername(ctx context.Context, username string, limit
 		return nil, err
 	}
 
-	var users []*model.FullUser
-	for _, user := range userMap {
-		users = append(users, user)
-	}
-
 	return users, nil
 }
 
@@ -443,248 +552,1249 @@ func maximumLimit(l *int) {
 	}
 }
 
-func (r *userRepo) FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error) {
+// FindUserFollowers lists the user's followers newest first, starting after the cursor.
+func (r *userRepo) FindUserFollowers(ctx context.Context, id uuid.UUID, after *model.FollowCursor, limit int) ([]*model.FullFollower, error) {
 	maximumLimit(&limit)
 
+	var (
+		afterFollowedAt *time.Time
+		afterID *uuid.UUID
+	)
+	if after != nil {
+		afterFollowedAt = &after.FollowedAt
+		afterID = &after.ID
+	}
+
 	rows, err := r.db.Query(
 		ctx,
 		`
-		SELECT f.follower_id, u.username, u.display_name, u.avatar_url, u.bio
+		SELECT f.follower_id, u.username, u.display_name, u.avatar_url, u.bio, f.followed_at
 		FROM followers f
 		JOIN users u ON f.follower_id = u.id
 		WHERE f.user_id = $1
-		LIMIT $2
-		OFFSET $3
+		AND ($2::timestamptz IS NULL OR (f.followed_at, f.follower_id) < ($2, $3))
+		ORDER BY f.followed_at DESC, f.follower_id DESC
+		LIMIT $4
 		`,
 		id,
+		afterFollowedAt,
+		afterID,
 		limit,
-		offset,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var followers []*model.FullFollower
+	return scanFollows(rows)
+}
+
+// FindMutualFollowers finds the followers of user that the viewer follows, most
+// followed first, and how many of them there are in total.
+func (r *userRepo) FindMutualFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int) ([]*model.FullFollower, int64, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT f.follower_id, u.username, u.display_name, u.avatar_url, u.bio, f.followed_at, count(*) OVER()
+		FROM followers f
+		JOIN followers vf ON vf.user_id = f.follower_id AND vf.follower_id = $1
+		JOIN users u ON f.follower_id = u.id
+		WHERE f.user_id = $2
+		ORDER BY u.followers DESC, f.follower_id
+		LIMIT $3
+		`,
+		viewerID,
+		userID,
+		limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		follows []*model.FullFollower
+		count int64
+	)
 	for rows.Next() {
-		var follower model.FullFollower
+		var follow model.FullFollower
 		if err := rows.Scan(
-			&follower.ID,
-			&follower.Username,
-			&follower.DisplayName,
-			&follower.AvatarHash,
-			&follower.Bio,
+			&follow.ID,
+			&follow.Username,
+			&follow.DisplayName,
+			&follow.AvatarHash,
+			&follow.Bio,
+			&follow.FollowedAt,
+			&count,
 		); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
-		followers = append(followers, &follower)
+		follows = append(follows, &follow)
 	}
 
 	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return follows, count, nil
+}
+
+// FindFollowersByIDs finds the followers of user among followerIDs, most followed first.
+func (r *userRepo) FindFollowersByIDs(ctx context.Context, userID uuid.UUID, followerIDs []uuid.UUID, limit int) ([]*model.FullFollower, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT f.follower_id, u.username, u.display_name, u.avatar_url, u.bio, f.followed_at
+		FROM followers f
+		JOIN users u ON f.follower_id = u.id
+		WHERE f.user_id = $1 AND f.follower_id = ANY($2)
+		ORDER BY u.followers DESC, f.follower_id
+		LIMIT $3
+		`,
+		userID,
+		followerIDs,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFollows(rows)
+}
+
+// FindFollowerIDs pages through the IDs of the user's followers in ID order.
+func (r *userRepo) FindFollowerIDs(ctx context.Context, userID uuid.UUID, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(
+		ctx,
+		"SELECT f.follower_id FROM followers f WHERE f.user_id = $1 AND f.follower_id > $2 ORDER BY f.follower_id LIMIT $3",
+		userID,
+		after,
+		limit,
+	)
+	if err != nil {
 		return nil, err
 	}
 
-	return followers, nil
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
 }
 
-func (r *userRepo) Follow(ctx context.Context, follower model.Follower) error {
-	tx, err := r.db.Begin(ctx)
+// FindFollowIDs pages through the IDs of the users the user follows in ID order.
+func (r *userRepo) FindFollowIDs(ctx context.Context, followerID uuid.UUID, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(
+		ctx,
+		"SELECT f.user_id FROM followers f WHERE f.follower_id = $1 AND f.user_id > $2 ORDER BY f.user_id LIMIT $3",
+		followerID,
+		after,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// StreamNotifiedFollowers passes the followers to notify about the user's new post
+// to fn, batchSize at a time: the followers that want all posts, and the ones that
+// want only highlighted posts when it's highlighted, that haven't muted the user.
+// The rows are fetched through a server-side cursor, so they never are all in memory.
+func (r *userRepo) StreamNotifiedFollowers(ctx context.Context, userID uuid.UUID, highlighted bool, batchSize int, fn func(followers []*model.NotifiedFollower) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	var exists bool
-	if err := tx.QueryRow(
+	if _, err := tx.Exec(
 		ctx,
 		`
-		SELECT EXISTS(SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)
+		DECLARE notified_followers NO SCROLL CURSOR FOR
+		SELECT f.follower_id, f.notification_channels, f.notification_digest
+		FROM followers f
+		WHERE f.user_id = $1
+		AND (f.notification_level = $2 OR ($3 AND f.notification_level = $4))
+		AND NOT EXISTS(SELECT 1 FROM mutes m WHERE m.user_id = f.follower_id AND m.muted_id = $1 AND (m.expires_at IS NULL OR m.expires_at > now()))
 		`,
-		follower.UserID, follower.FollowerID,
-	).Scan(&exists); err != nil {
+		userID,
+		model.NOTIFICATION_LEVEL_ALL,
+		highlighted,
+		model.NOTIFICATION_LEVEL_HIGHLIGHTED,
+	); err != nil {
 		return err
 	}
 
-	if exists {
-		return nil
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM notified_followers", batchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+
+		followers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.NotifiedFollower, error) {
+			var follower model.NotifiedFollower
+			if err := row.Scan(&follower.FollowerID, &follower.Channels, &follower.Digest); err != nil {
+				return nil, err
+			}
+			return &follower, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(followers) == 0 {
+			return nil
+		}
+
+		if err := fn(followers); err != nil {
+			return err
+		}
+	}
+}
+
+// Follow reports false when the follow already existed. The counts are left to
+// the caller, see redisrepo.Counters.
+func (r *userRepo) Follow(ctx context.Context, follower model.Follower) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
 	}
+	defer tx.Rollback(ctx)
 
-	_, err = tx.Exec(
+	inserted, err := tx.Exec(
 		ctx,
 		`
-		INSERT INTO followers(user_id, follower_id)
-		VALUES($1, $2)
+		INSERT INTO followers(user_id, follower_id, followed_at)
+		VALUES($1, $2, $3)
 		ON CONFLICT DO NOTHING
 		`,
 		follower.UserID,
 		follower.FollowerID,
+		time.Now(),
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	_, err = tx.Exec(ctx, "UPDATE users SET followers = followers + 1 WHERE id = $1", follower.UserID)
-	if err != nil {
-		return err
+	if inserted.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	return tx.Commit(ctx)
+	return true, tx.Commit(ctx)
 }
 
-func (r *userRepo) Unfollow(ctx context.Context, follower model.Follower) error {
+// Unfollow reports false when the follow didn't exist.
+func (r *userRepo) Unfollow(ctx context.Context, follower model.Follower) (bool, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback(ctx)
 
-	var exists bool
-	if err := tx.QueryRow(
+	deleted, err := tx.Exec(
 		ctx,
 		`
-		SELECT EXISTS(SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)
+		DELETE FROM followers
+		WHERE user_id = $1 AND follower_id = $2
 		`,
-		follower.UserID, follower.FollowerID,
-	).Scan(&exists); err != nil {
-		return err
+		follower.UserID,
+		follower.FollowerID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if deleted.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// AddFollowCounts flushes the count deltas that take takes, it returns the usernames
+// of the updated users. The deltas are taken under FOLLOW_COUNTS_LOCK, so that no
+// reconciliation batch counts them in between, ErrLocked is returned while another
+// flush or reconciliation batch holds it. The rows are locked in id order like every
+// other multi-row update of users, so that it can't deadlock with them.
+func (r *userRepo) AddFollowCounts(ctx context.Context, take func(ctx context.Context) (map[uuid.UUID]model.FollowCountDeltas, error)) (map[uuid.UUID]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tryAdvisoryXactLock(ctx, tx, FOLLOW_COUNTS_LOCK); err != nil {
+		return nil, err
+	}
+
+	deltas, err := take(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deltas) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(deltas))
+	followers := make([]int64, 0, len(deltas))
+	follows := make([]int64, 0, len(deltas))
+	for userID, delta := range deltas {
+		userIDs = append(userIDs, userID)
+		followers = append(followers, delta.Followers)
+		follows = append(follows, delta.Follows)
 	}
 
-	if !exists {
-		return nil
+	if _, err := tx.Exec(ctx, "SELECT id FROM users WHERE id = ANY($1) ORDER BY id FOR UPDATE", userIDs); err != nil {
+		return nil, err
 	}
 
-	_, err = tx.Exec(
+	rows, err := tx.Query(
 		ctx,
 		`
-		DELETE FROM followers
-		WHERE user_id = $1 AND follower_id = $2
+		UPDATE users u SET followers = u.followers + d.followers, follows = u.follows + d.follows
+		FROM unnest($1::uuid[], $2::bigint[], $3::bigint[]) AS d(id, followers, follows)
+		WHERE u.id = d.id
+		RETURNING u.id, u.username
 		`,
-		follower.UserID,
-		follower.FollowerID,
+		userIDs,
+		followers,
+		follows,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	_, err = tx.Exec(ctx, "UPDATE users SET followers = followers - 1 WHERE id = $1", follower.UserID)
-	if err != nil {
-		return err
+	usernames := make(map[uuid.UUID]string, len(deltas))
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			username string
+		)
+		if err := rows.Scan(&userID, &username); err != nil {
+			return nil, err
+		}
+
+		usernames[userID] = username
 	}
 
-	return tx.Commit(ctx)
-}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-func (r *userRepo) UpdateNewPostNotificationsEnabled(ctx context.Context, follower model.Follower) error {
-	_, err := r.db.Exec(ctx, "UPDATE followers SET new_post_notifications_enabled = $1 WHERE user_id = $2 AND follower_id = $3", follower.NewPostNotificationsEnabled, follower.UserID, follower.FollowerID)
-	return err
+	return usernames, tx.Commit(ctx)
 }
 
-func (r *userRepo) FindUserFollows(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error) {
-	maximumLimit(&limit)
+// ReconcileFollowCounts recomputes the followers and follows counts of up to
+// limit users after the ID from the followers table, fixing the ones that drifted.
+// The stored counts are expected to miss the deltas that pending finds, the
+// drifts compare the counts with the deltas to the actual ones. The batch runs under
+// FOLLOW_COUNTS_LOCK, so no flush takes deltas between pending and the recount,
+// ErrLocked is returned while a flush holds it. It returns the reconciled user
+// IDs, none when there are no users left, and the drifts among them.
+func (r *userRepo) ReconcileFollowCounts(ctx context.Context, after uuid.UUID, limit int, pending func(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.FollowCountDeltas, error)) ([]uuid.UUID, []*model.FollowCountDrift, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
 
-	rows, err := r.db.Query(
+	if err := tryAdvisoryXactLock(ctx, tx, FOLLOW_COUNTS_LOCK); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2 FOR UPDATE", after, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(userIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	pendingDeltas, err := pending(ctx, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pendingFollowers := make([]int64, len(userIDs))
+	pendingFollows := make([]int64, len(userIDs))
+	for i, userID := range userIDs {
+		pendingFollowers[i] = pendingDeltas[userID].Followers
+		pendingFollows[i] = pendingDeltas[userID].Follows
+	}
+
+	rows, err = tx.Query(
 		ctx,
 		`
-		SELECT f.follower_id, u.username, u.display_name, u.avatar_url, u.bio
-		FROM followers f
-		JOIN users u ON f.user_id = u.id
-		WHERE f.follower_id = $1
-		LIMIT $2
-		OFFSET $3
+		WITH actual AS (
+			SELECT
+			b.id,
+			(SELECT count(*) FROM followers f WHERE f.user_id = b.id) AS followers,
+			(SELECT count(*) FROM followers f WHERE f.follower_id = b.id) AS follows,
+			b.pending_followers,
+			b.pending_follows
+			FROM unnest($1::uuid[], $2::bigint[], $3::bigint[]) AS b(id, pending_followers, pending_follows)
+		)
+		UPDATE users u SET followers = a.followers - a.pending_followers, follows = a.follows - a.pending_follows
+		FROM actual a
+		JOIN users old ON old.id = a.id
+		WHERE u.id = a.id AND (u.followers <> a.followers - a.pending_followers OR u.follows <> a.follows - a.pending_follows)
+		RETURNING u.id, u.username, old.followers + a.pending_followers, a.followers, old.follows + a.pending_follows, a.follows
 		`,
-		id,
-		limit,
-		offset,
+		userIDs,
+		pendingFollowers,
+		pendingFollows,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var followers []*model.FullFollower
+	var drifts []*model.FollowCountDrift
 	for rows.Next() {
-		var follower model.FullFollower
+		var drift model.FollowCountDrift
 		if err := rows.Scan(
-			&follower.ID,
-			&follower.Username,
-			&follower.DisplayName,
-			&follower.AvatarHash,
-			&follower.Bio,
+			&drift.UserID,
+			&drift.Username,
+			&drift.Followers,
+			&drift.ActualFollowers,
+			&drift.Follows,
+			&drift.ActualFollows,
 		); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		followers = append(followers, &follower)
+		drifts = append(drifts, &drift)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return followers, nil
+	return userIDs, drifts, tx.Commit(ctx)
 }
 
-func (r *userRepo) ExistsWithID(ctx context.Context, id uuid.UUID) (bool, error) {
-	var exists bool
-	if err := r.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users u WHERE u.id = $1)", id).Scan(&exists); err != nil {
-		return false, err
+// UpdateNotificationPreferences returns the follower's preferences before and after
+// the update, pgx.ErrNoRows is returned when the follower doesn't follow the user.
+func (r *userRepo) UpdateNotificationPreferences(ctx context.Context, follower model.Follower, update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, *model.NotificationPreferences, error) {
+	var previous, preferences model.NotificationPreferences
+	if err := r.db.QueryRow(
+		ctx,
+		`
+		UPDATE followers f SET
+		notification_level = COALESCE($3, f.notification_level),
+		notification_channels = COALESCE($4, f.notification_channels),
+		notification_digest = COALESCE($5, f.notification_digest)
+		FROM followers old
+		WHERE f.user_id = $1 AND f.follower_id = $2
+		AND old.user_id = f.user_id AND old.follower_id = f.follower_id
+		RETURNING
+		old.notification_level, old.notification_channels, old.notification_digest,
+		f.notification_level, f.notification_channels, f.notification_digest
+		`,
+		follower.UserID,
+		follower.FollowerID,
+		update.Level,
+		update.Channels,
+		update.Digest,
+	).Scan(
+		&previous.Level,
+		&previous.Channels,
+		&previous.Digest,
+		&preferences.Level,
+		&preferences.Channels,
+		&preferences.Digest,
+	); err != nil {
+		return nil, nil, err
 	}
 
-	return exists, nil
+	return &previous, &preferences, nil
 }
 
-func (r *userRepo) ExistsWithUsername(ctx context.Context, username string) (bool, error) {
-	var exists bool
-	if err := r.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users u WHERE u.username = $1)", username).Scan(&exists); err != nil {
-		return false, err
+func (r *userRepo) FindRelationship(ctx context.Context, followerID uuid.UUID, userID uuid.UUID) (*model.Relationship, error) {
+	var (
+		notificationLevel *string
+		notificationChannels []string
+		notificationDigest *string
+		relationship model.Relationship
+	)
+	if err := r.db.QueryRow(
+		ctx,
+		`
+		SELECT
+		f.notification_level, f.notification_channels, f.notification_digest,
+		EXISTS(SELECT 1 FROM followers fb WHERE fb.user_id = $2 AND fb.follower_id = $1),
+		EXISTS(SELECT 1 FROM follow_requests fr WHERE fr.user_id = $1 AND fr.follower_id = $2),
+		EXISTS(SELECT 1 FROM blocks b WHERE b.user_id = $2 AND b.blocked_id = $1),
+		EXISTS(SELECT 1 FROM blocks b WHERE b.user_id = $1 AND b.blocked_id = $2),
+		EXISTS(SELECT 1 FROM mutes m WHERE m.user_id = $2 AND m.muted_id = $1 AND (m.expires_at IS NULL OR m.expires_at > now()))
+		FROM (SELECT 1) AS one
+		LEFT JOIN followers f ON f.user_id = $1 AND f.follower_id = $2
+		`,
+		userID,
+		followerID,
+	).Scan(
+		&notificationLevel,
+		&notificationChannels,
+		&notificationDigest,
+		&relationship.IsFollowedBy,
+		&relationship.FollowRequested,
+		&relationship.IsBlocking,
+		&relationship.IsBlockedBy,
+		&relationship.IsMuted,
+	); err != nil {
+		return nil, err
 	}
 
-	return exists, nil
+	setFollowing(&relationship, notificationLevel, notificationChannels, notificationDigest)
+
+	return &relationship, nil
 }
 
-func (r *userRepo) FindUserSocialLinks(ctx context.Context, userID uuid.UUID) ([]*model.SocialLink, error) {
+// setFollowing sets the follow state from the preferences of the follow, which
+// are null when the viewer doesn't follow the user.
+func setFollowing(relationship *model.Relationship, level *string, channels []string, digest *string) {
+	if level == nil {
+		return
+	}
+
+	relationship.IsFollowing = true
+	relationship.Notifications = &model.NotificationPreferences{
+		Level: *level,
+		Channels: channels,
+		Digest: *digest,
+	}
+}
+
+// FindRelationships is FindRelationship for many users at once, users that don't exist are left out.
+func (r *userRepo) FindRelationships(ctx context.Context, followerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.Relationship, error) {
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT
-		l.user_id, l.url, l.platform
-		FROM social_links l
-		WHERE l.user_id = $1
+		`
+		SELECT
+		u.id,
+		f.notification_level, f.notification_channels, f.notification_digest,
+		EXISTS(SELECT 1 FROM followers fb WHERE fb.user_id = $1 AND fb.follower_id = u.id),
+		EXISTS(SELECT 1 FROM follow_requests fr WHERE fr.user_id = u.id AND fr.follower_id = $1),
+		EXISTS(SELECT 1 FROM blocks b WHERE b.user_id = $1 AND b.blocked_id = u.id),
+		EXISTS(SELECT 1 FROM blocks b WHERE b.user_id = u.id AND b.blocked_id = $1),
+		EXISTS(SELECT 1 FROM mutes m WHERE m.user_id = $1 AND m.muted_id = u.id AND (m.expires_at IS NULL OR m.expires_at > now()))
+		FROM users u
+		LEFT JOIN followers f ON f.user_id = u.id AND f.follower_id = $1
+		WHERE u.id = ANY($2)
 		`,
-		userID,
+		followerID,
+		userIDs,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var links []*model.SocialLink
+	relationships := make(map[uuid.UUID]model.Relationship, len(userIDs))
 	for rows.Next() {
-		var link model.SocialLink
+		var (
+			userID uuid.UUID
+			notificationLevel *string
+			notificationChannels []string
+			notificationDigest *string
+			relationship model.Relationship
+		)
 		if err := rows.Scan(
-			&link.UserID,
-			&link.URL,
-			&link.Platform,
+			&userID,
+			&notificationLevel,
+			&notificationChannels,
+			&notificationDigest,
+			&relationship.IsFollowedBy,
+			&relationship.FollowRequested,
+			&relationship.IsBlocking,
+			&relationship.IsBlockedBy,
+			&relationship.IsMuted,
 		); err != nil {
 			return nil, err
 		}
 
-		links = append(links, &link)
+		setFollowing(&relationship, notificationLevel, notificationChannels, notificationDigest)
+
+		relationships[userID] = relationship
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return links, nil
-}
-
-func (r *userRepo) AddSocialLink(ctx context.Context, link model.SocialLink) error {
-	_, err := r.db.Exec(ctx, "INSERT INTO social_links(user_id, url, platform) VALUES($1, $2, $3)", link.UserID, link.URL, link.Platform)
-	return err
+	return relationships, nil
 }
 
-func (r *userRepo) DeleteSocialLink(ctx context.Context, userID uuid.UUID, platform string) error {
-	_, err := r.db.Exec(ctx, "DELETE FROM social_links l WHERE l.user_id = $1 AND l.platform = $2", userID, platform)
+func (r *userRepo) FindFollowStates(ctx context.Context, viewerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.FollowState, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT
+		u.id,
+		EXISTS(SELECT 1 FROM followers f WHERE f.user_id = u.id AND f.follower_id = $1),
+		EXISTS(SELECT 1 FROM followers f WHERE f.user_id = $1 AND f.follower_id = u.id)
+		FROM unnest($2::uuid[]) AS u(id)
+		`,
+		viewerID,
+		userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[uuid.UUID]model.FollowState, len(userIDs))
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			state model.FollowState
+		)
+		if err := rows.Scan(&userID, &state.ViewerFollows, &state.FollowsViewer); err != nil {
+			return nil, err
+		}
+		states[userID] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func (r *userRepo) CreateFollowRequest(ctx context.Context, follower model.Follower) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		INSERT INTO follow_requests(user_id, follower_id, created_at)
+		VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`,
+		follower.UserID,
+		follower.FollowerID,
+		time.Now(),
+	)
+	return err
+}
+
+func (r *userRepo) FindFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullFollowRequest, error) {
+	maximumLimit(&limit)
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT fr.follower_id, u.username, u.display_name, u.avatar_url, u.bio, fr.created_at
+		FROM follow_requests fr
+		JOIN users u ON fr.follower_id = u.id
+		WHERE fr.user_id = $1
+		ORDER BY fr.created_at DESC
+		LIMIT $2
+		OFFSET $3
+		`,
+		userID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*model.FullFollowRequest
+	for rows.Next() {
+		var request model.FullFollowRequest
+		if err := rows.Scan(
+			&request.ID,
+			&request.Username,
+			&request.DisplayName,
+			&request.AvatarHash,
+			&request.Bio,
+			&request.RequestedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, &request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ApproveFollowRequest turns the pending request into a follow, it reports
+// false when there was no such request or the follow already existed.
+func (r *userRepo) ApproveFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := tx.Exec(ctx, "DELETE FROM follow_requests WHERE user_id = $1 AND follower_id = $2", userID, followerID)
+	if err != nil {
+		return false, err
+	}
+	if deleted.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	inserted, err := tx.Exec(
+		ctx,
+		`
+		INSERT INTO followers(user_id, follower_id, followed_at)
+		VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`,
+		userID,
+		followerID,
+		time.Now(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return inserted.RowsAffected() > 0, tx.Commit(ctx)
+}
+
+// ApproveAllFollowRequests approves every pending request of the user and
+// returns the ids of the new followers.
+func (r *userRepo) ApproveAllFollowRequests(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(
+		ctx,
+		`
+		WITH approved AS (
+			DELETE FROM follow_requests WHERE user_id = $1 RETURNING follower_id
+		)
+		INSERT INTO followers(user_id, follower_id, followed_at)
+		SELECT $1, follower_id, $2 FROM approved
+		ON CONFLICT DO NOTHING
+		RETURNING follower_id
+		`,
+		userID,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var followerIDs []uuid.UUID
+	for rows.Next() {
+		var followerID uuid.UUID
+		if err := rows.Scan(&followerID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		followerIDs = append(followerIDs, followerID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return followerIDs, tx.Commit(ctx)
+}
+
+// DeleteFollowRequest rejects or cancels a pending request, it reports false
+// when there was no such request.
+func (r *userRepo) DeleteFollowRequest(ctx context.Context, userID uuid.UUID, followerID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM follow_requests WHERE user_id = $1 AND follower_id = $2", userID, followerID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Block removes the follows and follow requests between the users in both
+// directions and returns the removed follows. It reports false and changes
+// nothing when the user has already blocked blockedID.
+func (r *userRepo) Block(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) ([]model.Follower, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(
+		ctx,
+		`
+		INSERT INTO blocks(user_id, blocked_id, created_at)
+		VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		`,
+		userID,
+		blockedID,
+		time.Now(),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil, false, nil
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`
+		DELETE FROM followers
+		WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		RETURNING user_id, follower_id
+		`,
+		userID,
+		blockedID,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var removed []model.Follower
+	for rows.Next() {
+		var follower model.Follower
+		if err := rows.Scan(&follower.UserID, &follower.FollowerID); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		removed = append(removed, follower)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		DELETE FROM follow_requests
+		WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		`,
+		userID,
+		blockedID,
+	); err != nil {
+		return nil, false, err
+	}
+
+	return removed, true, tx.Commit(ctx)
+}
+
+func (r *userRepo) Unblock(ctx context.Context, userID uuid.UUID, blockedID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM blocks WHERE user_id = $1 AND blocked_id = $2", userID, blockedID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FindBlockerIDs returns which of userIDs have blocked blockedID.
+func (r *userRepo) FindBlockerIDs(ctx context.Context, blockedID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, "SELECT b.user_id FROM blocks b WHERE b.blocked_id = $1 AND b.user_id = ANY($2)", blockedID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blockerIDs []uuid.UUID
+	for rows.Next() {
+		var blockerID uuid.UUID
+		if err := rows.Scan(&blockerID); err != nil {
+			return nil, err
+		}
+		blockerIDs = append(blockerIDs, blockerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blockerIDs, nil
+}
+
+// Mute mutes the user or replaces the expiry of an existing mute.
+func (r *userRepo) Mute(ctx context.Context, mute model.Mute) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		INSERT INTO mutes(user_id, muted_id, created_at, expires_at)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT (user_id, muted_id) DO UPDATE SET created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		`,
+		mute.UserID,
+		mute.MutedID,
+		time.Now(),
+		mute.ExpiresAt,
+	)
+	return err
+}
+
+func (r *userRepo) Unmute(ctx context.Context, userID uuid.UUID, mutedID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM mutes WHERE user_id = $1 AND muted_id = $2", userID, mutedID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *userRepo) FindMutes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullMute, error) {
+	maximumLimit(&limit)
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT m.muted_id, u.username, u.display_name, u.avatar_url, u.bio, m.created_at, m.expires_at
+		FROM mutes m
+		JOIN users u ON m.muted_id = u.id
+		WHERE m.user_id = $1 AND (m.expires_at IS NULL OR m.expires_at > now())
+		ORDER BY m.created_at DESC
+		LIMIT $2
+		OFFSET $3
+		`,
+		userID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mutes []*model.FullMute
+	for rows.Next() {
+		var mute model.FullMute
+		if err := rows.Scan(
+			&mute.ID,
+			&mute.Username,
+			&mute.DisplayName,
+			&mute.AvatarHash,
+			&mute.Bio,
+			&mute.MutedAt,
+			&mute.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+
+		mutes = append(mutes, &mute)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mutes, nil
+}
+
+// FindUserFollows lists the users the user follows newest first, starting after the cursor.
+func (r *userRepo) FindUserFollows(ctx context.Context, id uuid.UUID, after *model.FollowCursor, limit int) ([]*model.FullFollower, error) {
+	maximumLimit(&limit)
+
+	var (
+		afterFollowedAt *time.Time
+		afterID *uuid.UUID
+	)
+	if after != nil {
+		afterFollowedAt = &after.FollowedAt
+		afterID = &after.ID
+	}
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT f.user_id, u.username, u.display_name, u.avatar_url, u.bio, f.followed_at
+		FROM followers f
+		JOIN users u ON f.user_id = u.id
+		WHERE f.follower_id = $1
+		AND ($2::timestamptz IS NULL OR (f.followed_at, f.user_id) < ($2, $3))
+		ORDER BY f.followed_at DESC, f.user_id DESC
+		LIMIT $4
+		`,
+		id,
+		afterFollowedAt,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFollows(rows)
+}
+
+func scanFollows(rows pgx.Rows) ([]*model.FullFollower, error) {
+	var follows []*model.FullFollower
+	for rows.Next() {
+		var follow model.FullFollower
+		if err := rows.Scan(
+			&follow.ID,
+			&follow.Username,
+			&follow.DisplayName,
+			&follow.AvatarHash,
+			&follow.Bio,
+			&follow.FollowedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, &follow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+func (r *userRepo) ExistsWithID(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users u WHERE u.id = $1)", id).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *userRepo) ExistsWithUsername(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users u WHERE u.username = $1)", username).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *userRepo) FindUserSocialLinks(ctx context.Context, userID uuid.UUID) ([]*model.SocialLink, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		l.user_id, l.url, l.platform, l.verified, l.verified_at, l.position, l.label
+		FROM social_links l
+		WHERE l.user_id = $1
+		ORDER BY l.position
+		`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*model.SocialLink
+	for rows.Next() {
+		var link model.SocialLink
+		if err := rows.Scan(
+			&link.UserID,
+			&link.URL,
+			&link.Platform,
+			&link.Verified,
+			&link.VerifiedAt,
+			&link.Position,
+			&link.Label,
+		); err != nil {
+			return nil, err
+		}
+
+		links = append(links, &link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// AddSocialLink adds the link last. Like the other social link changes it bumps the
+// user's updated_at, as the links are a part of the profile that its ETag validates.
+func (r *userRepo) AddSocialLink(ctx context.Context, link model.SocialLink) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		WITH touched AS (
+			UPDATE users SET updated_at = now() WHERE id = $1
+		)
+		INSERT INTO social_links(user_id, url, platform, label, position)
+		VALUES($1, $2, $3, $4, (SELECT COALESCE(MAX(l.position) + 1, 0) FROM social_links l WHERE l.user_id = $1))
+		`,
+		link.UserID,
+		link.URL,
+		link.Platform,
+		link.Label,
+	)
+	return err
+}
+
+// UpdateSocialLink updates the link's url and label, a changed url has to be verified again.
+func (r *userRepo) UpdateSocialLink(ctx context.Context, link model.SocialLink) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		WITH touched AS (
+			UPDATE users SET updated_at = now() WHERE id = $3
+		)
+		UPDATE social_links
+		SET
+		url = $1,
+		label = $2,
+		verified = CASE WHEN url = $1 THEN verified ELSE false END,
+		verified_at = CASE WHEN url = $1 THEN verified_at ELSE NULL END,
+		checked_at = CASE WHEN url = $1 THEN checked_at ELSE NULL END,
+		failed_checks = CASE WHEN url = $1 THEN failed_checks ELSE 0 END,
+		next_check_at = CASE WHEN url = $1 THEN next_check_at ELSE NULL END
+		WHERE user_id = $3 AND platform = $4
+		`,
+		link.URL,
+		link.Label,
+		link.UserID,
+		link.Platform,
+	)
+	return err
+}
+
+// ReorderSocialLinks sets every link's position to the index of its platform in platforms.
+func (r *userRepo) ReorderSocialLinks(ctx context.Context, userID uuid.UUID, platforms []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for position, platform := range platforms {
+		if _, err := tx.Exec(
+			ctx,
+			"UPDATE social_links SET position = $1 WHERE user_id = $2 AND platform = $3",
+			position,
+			userID,
+			platform,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE users SET updated_at = now() WHERE id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *userRepo) DeleteSocialLink(ctx context.Context, userID uuid.UUID, platform string) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		WITH touched AS (
+			UPDATE users SET updated_at = now() WHERE id = $1
+		)
+		DELETE FROM social_links l WHERE l.user_id = $1 AND l.platform = $2
+		`,
+		userID,
+		platform,
+	)
+	return err
+}
+
+func (r *userRepo) FindSocialLinksToVerify(ctx context.Context, now time.Time, limit int) ([]*model.SocialLinkToVerify, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT l.user_id, l.url, l.platform, l.verified, l.verified_at, u.username, l.failed_checks
+		FROM social_links l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.next_check_at IS NULL OR l.next_check_at <= $1
+		ORDER BY l.next_check_at NULLS FIRST
+		LIMIT $2
+		`,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*model.SocialLinkToVerify
+	for rows.Next() {
+		var link model.SocialLinkToVerify
+		if err := rows.Scan(
+			&link.UserID,
+			&link.URL,
+			&link.Platform,
+			&link.Verified,
+			&link.VerifiedAt,
+			&link.Username,
+			&link.FailedChecks,
+		); err != nil {
+			return nil, err
+		}
+
+		links = append(links, &link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// UpdateSocialLinkVerification doesn't touch the link if its url has changed since it was checked.
+// The user's updated_at is only bumped when the link's verification has changed.
+func (r *userRepo) UpdateSocialLinkVerification(ctx context.Context, link model.SocialLink, checkedAt time.Time, nextCheckAt time.Time) error {
+	_, err := r.db.Exec(
+		ctx,
+		`
+		WITH updated AS (
+			UPDATE social_links l
+			SET verified = $1, verified_at = $2, checked_at = $3, failed_checks = 0, next_check_at = $7
+			FROM social_links old
+			WHERE l.user_id = $4 AND l.platform = $5 AND l.url = $6
+			AND old.user_id = l.user_id AND old.platform = l.platform
+			RETURNING old.verified <> l.verified AS changed
+		)
+		UPDATE users SET updated_at = now()
+		WHERE id = $4 AND EXISTS(SELECT 1 FROM updated WHERE changed)
+		`,
+		link.Verified,
+		link.VerifiedAt,
+		checkedAt,
+		link.UserID,
+		link.Platform,
+		link.URL,
+		nextCheckAt,
+	)
+	return err
+}
+
+// FailSocialLinkCheck leaves the link's verification as it is and only schedules the next check.
+func (r *userRepo) FailSocialLinkCheck(ctx context.Context, link model.SocialLink, nextCheckAt time.Time) error {
+	_, err := r.db.Exec(
+		ctx,
+		"UPDATE social_links SET failed_checks = failed_checks + 1, next_check_at = $1 WHERE user_id = $2 AND platform = $3 AND url = $4",
+		nextCheckAt,
+		link.UserID,
+		link.Platform,
+		link.URL,
+	)
 	return err
 }
@@ -0,0 +1,198 @@
+package redisrepo
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// FOLLOW_COUNT_SHARDS is how many keys a user's count delta is spread over, so
+// that the follows of a popular user don't all hit the same key.
+const FOLLOW_COUNT_SHARDS = 8
+
+type Counters interface {
+	IncrFollowCounts(ctx context.Context, deltas map[string]model.FollowCountDeltas) error
+	PendingFollowCounts(ctx context.Context, userIDs []string) (map[string]model.FollowCountDeltas, error)
+	TakeFollowCounts(ctx context.Context, userID string) (model.FollowCountDeltas, error)
+	PopDirtyFollowCounts(ctx context.Context, count int) ([]string, error)
+	MarkFollowCountsDirty(ctx context.Context, userIDs []string) error
+	FlushGeneration(ctx context.Context) (string, error)
+	BumpFlushGeneration(ctx context.Context) error
+	SetJSONIfFlushGeneration(ctx context.Context, generation string, key string, value interface{}, ttl time.Duration) error
+}
+
+// setIfFlushGenerationScript sets KEYS[2] to ARGV[2] for ARGV[3] milliseconds unless
+// the flush generation in KEYS[1] has changed from ARGV[1].
+var setIfFlushGenerationScript = redis.NewScript(`
+if (redis.call("GET", KEYS[1]) or "") ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+type countersRepo struct {
+	rdb *redis.Client
+}
+
+func newCountersRepo(rdb *redis.Client) Counters {
+	return &countersRepo{
+		rdb: rdb,
+	}
+}
+
+// IncrFollowCounts adds the deltas of every user to a random shard and marks the users dirty.
+func (r *countersRepo) IncrFollowCounts(ctx context.Context, deltas map[string]model.FollowCountDeltas) error {
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for userID, delta := range deltas {
+			shard := rand.IntN(FOLLOW_COUNT_SHARDS)
+			if delta.Followers != 0 {
+				pipe.IncrBy(ctx, FollowersDeltaKey(userID, shard), delta.Followers)
+			}
+			if delta.Follows != 0 {
+				pipe.IncrBy(ctx, FollowsDeltaKey(userID, shard), delta.Follows)
+			}
+			pipe.SAdd(ctx, FOLLOW_COUNTS_DIRTY_KEY, userID)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *countersRepo) PendingFollowCounts(ctx context.Context, userIDs []string) (map[string]model.FollowCountDeltas, error) {
+	pending := make(map[string]model.FollowCountDeltas, len(userIDs))
+	if len(userIDs) == 0 {
+		return pending, nil
+	}
+
+	keys := make([]string, 0, len(userIDs) * FOLLOW_COUNT_SHARDS * 2)
+	for _, userID := range userIDs {
+		keys = append(keys, followCountDeltaKeys(userID)...)
+	}
+
+	values, err := r.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, userID := range userIDs {
+		pending[userID] = sumFollowCountDeltas(values[i * FOLLOW_COUNT_SHARDS * 2 : (i + 1) * FOLLOW_COUNT_SHARDS * 2])
+	}
+
+	return pending, nil
+}
+
+// TakeFollowCounts reads and resets the user's deltas.
+func (r *countersRepo) TakeFollowCounts(ctx context.Context, userID string) (model.FollowCountDeltas, error) {
+	keys := followCountDeltaKeys(userID)
+
+	cmds, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.GetDel(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return model.FollowCountDeltas{}, err
+	}
+
+	values := make([]interface{}, len(cmds))
+	for i, cmd := range cmds {
+		value, err := cmd.(*redis.StringCmd).Result()
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return model.FollowCountDeltas{}, err
+		}
+		values[i] = value
+	}
+
+	return sumFollowCountDeltas(values), nil
+}
+
+func (r *countersRepo) PopDirtyFollowCounts(ctx context.Context, count int) ([]string, error) {
+	return r.rdb.SPopN(ctx, FOLLOW_COUNTS_DIRTY_KEY, int64(count)).Result()
+}
+
+func (r *countersRepo) MarkFollowCountsDirty(ctx context.Context, userIDs []string) error {
+	members := make([]interface{}, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = userID
+	}
+
+	return r.rdb.SAdd(ctx, FOLLOW_COUNTS_DIRTY_KEY, members...).Err()
+}
+
+// FlushGeneration changes with every flush of the counts to postgres, it is empty
+// before the first one.
+func (r *countersRepo) FlushGeneration(ctx context.Context) (string, error) {
+	generation, err := r.rdb.Get(ctx, FOLLOW_COUNTS_FLUSH_GENERATION_KEY).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	return generation, nil
+}
+
+func (r *countersRepo) BumpFlushGeneration(ctx context.Context) error {
+	return r.rdb.Incr(ctx, FOLLOW_COUNTS_FLUSH_GENERATION_KEY).Err()
+}
+
+// SetJSONIfFlushGeneration caches a value holding counts read from postgres, unless the
+// counts have been flushed since the generation was read, as the value may be older
+// than the flush then and the flush has already deleted the cache it would replace.
+func (r *countersRepo) SetJSONIfFlushGeneration(ctx context.Context, generation string, key string, value interface{}, ttl time.Duration) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return setIfFlushGenerationScript.Run(
+		ctx,
+		r.rdb,
+		[]string{FOLLOW_COUNTS_FLUSH_GENERATION_KEY, key},
+		generation,
+		valueJSON,
+		ttl.Milliseconds(),
+	).Err()
+}
+
+// followCountDeltaKeys are the followers shards followed by the follows shards.
+func followCountDeltaKeys(userID string) []string {
+	keys := make([]string, 0, FOLLOW_COUNT_SHARDS * 2)
+	for shard := 0; shard < FOLLOW_COUNT_SHARDS; shard++ {
+		keys = append(keys, FollowersDeltaKey(userID, shard))
+	}
+	for shard := 0; shard < FOLLOW_COUNT_SHARDS; shard++ {
+		keys = append(keys, FollowsDeltaKey(userID, shard))
+	}
+	return keys
+}
+
+func sumFollowCountDeltas(values []interface{}) model.FollowCountDeltas {
+	var deltas model.FollowCountDeltas
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		delta, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if i < FOLLOW_COUNT_SHARDS {
+			deltas.Followers += delta
+		} else {
+			deltas.Follows += delta
+		}
+	}
+	return deltas
+}
@@ -0,0 +1,66 @@
+package redisrepo
+
+import (
+	"testing"
+
+	"github.com/BloggingApp/user-service/internal/model"
+)
+
+func TestFollowCountDeltaKeys(t *testing.T) {
+	keys := followCountDeltaKeys("alice")
+
+	if len(keys) != FOLLOW_COUNT_SHARDS * 2 {
+		t.Fatalf("len(followCountDeltaKeys()) = %d, want %d", len(keys), FOLLOW_COUNT_SHARDS * 2)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for shard := 0; shard < FOLLOW_COUNT_SHARDS; shard++ {
+		if keys[shard] != FollowersDeltaKey("alice", shard) {
+			t.Errorf("keys[%d] = %q, want %q", shard, keys[shard], FollowersDeltaKey("alice", shard))
+		}
+		if keys[FOLLOW_COUNT_SHARDS + shard] != FollowsDeltaKey("alice", shard) {
+			t.Errorf("keys[%d] = %q, want %q", FOLLOW_COUNT_SHARDS + shard, keys[FOLLOW_COUNT_SHARDS + shard], FollowsDeltaKey("alice", shard))
+		}
+	}
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("key %q is repeated", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestSumFollowCountDeltas(t *testing.T) {
+	// values builds MGET results, the followers shards followed by the follows shards
+	values := func(followers []interface{}, follows []interface{}) []interface{} {
+		result := make([]interface{}, FOLLOW_COUNT_SHARDS * 2)
+		copy(result, followers)
+		copy(result[FOLLOW_COUNT_SHARDS:], follows)
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   model.FollowCountDeltas
+	}{
+		{name: "no keys", values: values(nil, nil), want: model.FollowCountDeltas{}},
+		{name: "one shard each", values: values([]interface{}{"3"}, []interface{}{"-2"}), want: model.FollowCountDeltas{Followers: 3, Follows: -2}},
+		{
+			name: "shards summed",
+			values: values([]interface{}{"1", nil, "4", "-1"}, []interface{}{nil, "2", "2"}),
+			want: model.FollowCountDeltas{Followers: 4, Follows: 4},
+		},
+		{name: "last shards", values: values([]interface{}{nil, nil, nil, nil, nil, nil, nil, "5"}, []interface{}{nil, nil, nil, nil, nil, nil, nil, "7"}), want: model.FollowCountDeltas{Followers: 5, Follows: 7}},
+		{name: "invalid values skipped", values: values([]interface{}{"x", "2", 3}, []interface{}{"1.5", "1"}), want: model.FollowCountDeltas{Followers: 2, Follows: 1}},
+		{name: "empty", values: nil, want: model.FollowCountDeltas{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sumFollowCountDeltas(tt.values); got != tt.want {
+				t.Errorf("sumFollowCountDeltas() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
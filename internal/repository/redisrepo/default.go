@@ -8,6 +8,22 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// expireIfEqualsScript sets the ttl of KEYS[1] to ARGV[2] milliseconds if its value is ARGV[1].
+var expireIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+`)
+
+// delIfEqualsScript deletes KEYS[1] if its value is ARGV[1].
+var delIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
 type defaultRepo struct {
 	rdb *redis.Client
 }
@@ -22,6 +38,10 @@ func (r *defaultRepo) Set(ctx context.Context, key string, value interface{}, tt
 	return r.rdb.Set(ctx, key, value, ttl).Err()
 }
 
+func (r *defaultRepo) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
 func (r *defaultRepo) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	valueJSON, err := json.Marshal(value)
 	if err != nil {
@@ -35,6 +55,11 @@ func (r *defaultRepo) Get(ctx context.Context, key string) *redis.StringCmd {
 	return r.rdb.Get(ctx, key)
 }
 
+// ExpireIfEquals reports false when the key doesn't exist anymore or holds another value.
+func (r *defaultRepo) ExpireIfEquals(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	return expireIfEqualsScript.Run(ctx, r.rdb, []string{key}, value, ttl.Milliseconds()).Bool()
+}
+
 func Get[T any](r Default, ctx context.Context, key string) (*T, error) {
 	value, err := r.Get(ctx, key).Result()
 	if err != nil {
@@ -74,3 +99,8 @@ func GetMany[T any](r Default, ctx context.Context, key string) ([]*T, error) {
 func (r *defaultRepo) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	return r.rdb.Del(ctx, keys...)
 }
+
+// DelIfEquals reports false when the key doesn't exist anymore or holds another value.
+func (r *defaultRepo) DelIfEquals(ctx context.Context, key string, value string) (bool, error) {
+	return delIfEqualsScript.Run(ctx, r.rdb, []string{key}, value).Bool()
+}
@@ -8,12 +8,21 @@ const (
 	TEMP_REGISTRATION_CODE_KEY = "registration-code:%d" // <registration code>
 	TEMP_SIGNIN_CODE_KEY = "sign-in-code:%d" // <sign-in code>
 	SEARCH_RESULTS_KEY = "search-results:%s:%d:%d" // <any word>:<limit>:<offset>
-	USER_FOLLOWERS_KEY = "user-followers:%s:%d:%d" // <userID>:<limit>:<offset>
-	USER_FOLLOWS_KEY = "user-follows:%s:%d:%d" // <userID>:<limit>:<offset>
-	IS_FOLLOWING_KEY = "%s-is-following:%s" // <followerID>:<userID>
+	USER_FOLLOWERS_KEY = "user-followers:%s" // <userID>, the first page only
+	USER_FOLLOWS_KEY = "user-follows:%s" // <userID>, the first page only
+	USER_FOLLOWER_IDS_KEY = "user-follower-ids:%s" // <userID>, a set
+	USER_FOLLOW_IDS_KEY = "user-follow-ids:%s" // <userID>, a set
 	PREPARE_USERNAME_KEY = "%s-prepare-for-registration" // <username>
 	PREPARE_USER_EMAIL_KEY = "%s-prepare-for-registration" // <email>
 	USER_FORGOT_PASSWORD_CODE_KEY = "forgot-password-code:%d" // <code>
+	FOLLOW_COUNTS_RECONCILIATION_KEY = "follow-counts-reconciliation" // the last run
+	FOLLOWERS_DELTA_KEY = "followers-delta:%s:%d" // <userID>:<shard>
+	FOLLOWS_DELTA_KEY = "follows-delta:%s:%d" // <userID>:<shard>
+	FOLLOW_COUNTS_DIRTY_KEY = "follow-counts-dirty" // a set of users with deltas to flush
+	FOLLOW_COUNTS_FLUSH_GENERATION_KEY = "follow-counts-flush-generation" // bumped by every flush
+	FOLLOW_IMPORT_KEY = "follow-import:%s" // <importID>
+	ACTIVE_FOLLOW_IMPORT_KEY = "active-follow-import:%s" // <userID>
+	SUGGESTIONS_RECOMPUTE_KEY = "suggestions-recompute:%s" // <userID>, set while a recompute is queued
 )
 
 func UserKey(userID string) string {
@@ -36,16 +45,36 @@ func SearchResultsKey(word string, limit int, offset int) string {
 	return fmt.Sprintf(SEARCH_RESULTS_KEY, word, limit, offset)
 }
 
-func UserFollowersKey(userID string, limit int, offset int) string {
-	return fmt.Sprintf(USER_FOLLOWERS_KEY, userID, limit, offset)
+func UserFollowersKey(userID string) string {
+	return fmt.Sprintf(USER_FOLLOWERS_KEY, userID)
 }
 
-func UserFollowsKey(userID string, limit int, offset int) string {
-	return fmt.Sprintf(USER_FOLLOWS_KEY, userID, limit, offset)
+func UserFollowsKey(userID string) string {
+	return fmt.Sprintf(USER_FOLLOWS_KEY, userID)
 }
 
-func IsFollowingKey(followerID string, userID string) string {
-	return fmt.Sprintf(IS_FOLLOWING_KEY, followerID, userID)
+func UserFollowerIDsKey(userID string) string {
+	return fmt.Sprintf(USER_FOLLOWER_IDS_KEY, userID)
+}
+
+func UserFollowIDsKey(userID string) string {
+	return fmt.Sprintf(USER_FOLLOW_IDS_KEY, userID)
+}
+
+func FollowersDeltaKey(userID string, shard int) string {
+	return fmt.Sprintf(FOLLOWERS_DELTA_KEY, userID, shard)
+}
+
+func FollowsDeltaKey(userID string, shard int) string {
+	return fmt.Sprintf(FOLLOWS_DELTA_KEY, userID, shard)
+}
+
+func FollowImportKey(importID string) string {
+	return fmt.Sprintf(FOLLOW_IMPORT_KEY, importID)
+}
+
+func ActiveFollowImportKey(userID string) string {
+	return fmt.Sprintf(ACTIVE_FOLLOW_IMPORT_KEY, userID)
 }
 
 func PrepareUsernameKey(username string) string {
@@ -59,3 +88,7 @@ func PrepareUserEmailKey(email string) string {
 func UserForgotPasswordCodeKey(code int) string {
 	return fmt.Sprintf(USER_FORGOT_PASSWORD_CODE_KEY, code)
 }
+
+func SuggestionsRecomputeKey(userID string) string {
+	return fmt.Sprintf(SUGGESTIONS_RECOMPUTE_KEY, userID)
+}
@@ -9,17 +9,24 @@ import (
 
 type Default interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
 	SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Get(ctx context.Context, key string) *redis.StringCmd
+	ExpireIfEquals(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	DelIfEquals(ctx context.Context, key string, value string) (bool, error)
 }
 
 type RedisRepository struct {
 	Default
+	Sets
+	Counters
 }
 
 func New(rdb *redis.Client) *RedisRepository {
 	return &RedisRepository{
 		Default: newDefaultRepo(rdb),
+		Sets: newSetsRepo(rdb),
+		Counters: newCountersRepo(rdb),
 	}
 }
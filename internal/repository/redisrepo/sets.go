@@ -0,0 +1,80 @@
+package redisrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SET_PLACEHOLDER is kept in every cached set so that an empty set still exists.
+const SET_PLACEHOLDER = "-"
+
+type Sets interface {
+	AddToSet(ctx context.Context, key string, members []string, ttl time.Duration) error
+	PublishSet(ctx context.Context, buildKey string, key string, ttl time.Duration) error
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	SInter(ctx context.Context, keys ...string) ([]string, error)
+}
+
+type setsRepo struct {
+	rdb *redis.Client
+}
+
+func newSetsRepo(rdb *redis.Client) Sets {
+	return &setsRepo{
+		rdb: rdb,
+	}
+}
+
+// AddToSet adds a chunk of a set that is being built at key, the ttl cleans up
+// the sets whose building was abandoned.
+func (r *setsRepo) AddToSet(ctx context.Context, key string, members []string, ttl time.Duration) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, values...)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// PublishSet replaces the set at key with the one built at buildKey at once,
+// so that a set is never read half built.
+func (r *setsRepo) PublishSet(ctx context.Context, buildKey string, key string, ttl time.Duration) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, buildKey, SET_PLACEHOLDER)
+		pipe.Rename(ctx, buildKey, key)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *setsRepo) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return r.rdb.Exists(ctx, keys...).Result()
+}
+
+func (r *setsRepo) SInter(ctx context.Context, keys ...string) ([]string, error) {
+	members, err := r.rdb.SInter(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := members[:0]
+	for _, member := range members {
+		if member != SET_PLACEHOLDER {
+			result = append(result, member)
+		}
+	}
+
+	return result, nil
+}
@@ -122,7 +122,7 @@ func (s *authService) SendRegistrationCode(ctx context.Context, input dto.Create
 	input.Email = strings.TrimSpace(input.Email)
 	input.Username = strings.TrimSpace(strings.ToLower(input.Username))
 
-	if strings.ContainsAny(input.Username, " !@#№$;%^:&?*()-/\\|,<>`~+=") {
+	if strings.ContainsAny(input.Username, USERNAME_FORBIDDEN_CHARACTERS) {
 		return ErrUsernameCannotContainSpecialCharacters
 	}
 
@@ -217,6 +217,11 @@ func (s *authService) VerifyRegistrationCodeAndCreateUser(ctx context.Context, c
 		s.logger.Sugar().Errorf("failed to delete user(%s) prepare keys from redis: %s", createdUser.ID, err.Error())
 	}
 
+	// A missing default avatar shouldn't fail the registration, the user can set one later
+	if err := s.userService.SetGeneratedAvatar(ctx, model.FullUser{ID: createdUser.ID, Username: createdUser.Username}); err != nil {
+		s.logger.Sugar().Errorf("failed to set generated avatar for user(%s): %s", createdUser.ID.String(), err.Error())
+	}
+
 	user, err := s.userService.FindByUsername(ctx, nil, createdUser.Username)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to retrieve user by username(%s) from postgres: %s", createdUser.Username, err.Error())
@@ -326,6 +331,8 @@ func (s *authService) VerifySignInCodeAndSignIn(ctx context.Context, code int) (
 		return nil, nil, err
 	}
 
+	s.touchLastActiveAt(ctx, user.ID)
+
 	return user, jwtPair, nil
 }
 
@@ -372,9 +379,19 @@ func (s *authService) RefreshTokens(ctx context.Context, refreshToken string) (*
 		return nil, ErrInternal
 	}
 
+	s.touchLastActiveAt(ctx, user.ID)
+
 	return jwtPair, nil
 }
 
+// touchLastActiveAt records that the user is active, which only affects follow
+// suggestions, so a failure doesn't fail the request.
+func (s *authService) touchLastActiveAt(ctx context.Context, userID uuid.UUID) {
+	if err := s.repo.Postgres.User.UpdateLastActiveAt(ctx, userID); err != nil {
+		s.logger.Sugar().Errorf("failed to update user(%s) last_active_at in postgres: %s", userID.String(), err.Error())
+	}
+}
+
 func (s *authService) UpdatePassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
 	user, err := s.repo.Postgres.User.FindPassword(ctx, userID)
 	if err != nil {
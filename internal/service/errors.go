@@ -12,6 +12,12 @@ var (
 	ErrUnauthorized = errors.New("user is not authorized")
 	ErrFollowToYourself = errors.New("you cannot follow to yourself")
 	ErrAlreadyFollowing = errors.New("you are already following this user")
+	ErrFollowRequestNotFound = errors.New("follow request not found")
+	ErrFollowBlocked = errors.New("you cannot follow this user")
+	ErrBlockYourself = errors.New("you cannot block yourself")
+	ErrAlreadyBlocked = errors.New("you have already blocked this user")
+	ErrMuteYourself = errors.New("you cannot mute yourself")
+	ErrMuteExpiresInPast = errors.New("mute must expire in the future")
 	ErrCooldown = errors.New("cooldown")
 	ErrFieldsNotAllowedToUpdate = errors.New("these fields are not allowed to be updated")
 	ErrUserWithUsernameAlreadyExists = errors.New("user with this username is already exists")
@@ -19,9 +25,37 @@ var (
 	ErrUserAlreadyExists = errors.New("user with this email or username is already exists")
 	ErrFileMustBeImage = errors.New("file must be image")
 	ErrFileMustHaveValidExtension = errors.New("file must have a valid extension")
-	ErrFailedToUploadAvatarToCDN = errors.New("failed to upload avatar to cdn")
+	ErrImageTooLarge = errors.New("image file is too large")
+	ErrImageDimensionsTooLarge = errors.New("image dimensions are too large")
+	ErrInvalidCropRect = errors.New("crop rectangle must be a square inside the image")
+	ErrInvalidBannerAspectRatio = errors.New("banner must have an aspect ratio close to 3:1")
+	ErrFailedToUploadToCDN = errors.New("failed to upload file to cdn")
 	ErrMaxSocialLinksAchieved = errors.New("maximum count of social links achieved")
 	ErrLinkHasInvalidType = errors.New("the link has invalid type")
+	ErrLinkIsNotValidURL = errors.New("the link is not a valid url")
+	ErrSocialLinkNotFound = errors.New("social link not found")
+	ErrSocialLinkAlreadySet = errors.New("a link of this platform has already been set")
+	ErrSocialLinkPlatformMismatch = errors.New("the new url belongs to another platform")
+	ErrSocialLinkURLCannotBeNull = errors.New("url cannot be null")
+	ErrInvalidSocialLinksOrder = errors.New("order must list every social link platform exactly once")
 	ErrInvalidOldPassword = errors.New("invalid old password")
 	ErrInvalidForgotPasswordCode = errors.New("invalid code")
+	ErrReportYourself = errors.New("you cannot report yourself")
+	ErrReportNotFound = errors.New("report not found")
+	ErrReportAlreadyClosed = errors.New("report is already closed")
+	ErrInvalidReportTransition = errors.New("report cannot be moved to this status")
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrRelationshipWithYourself = errors.New("you cannot have a relationship with yourself")
+	ErrTooManyIDs = errors.New("too many IDs, the maximum is 100")
+	ErrReconciliationRunning = errors.New("follow counts reconciliation is already running")
+	ErrNoReconciliation = errors.New("follow counts have never been reconciled")
+	ErrFollowImportTooLarge = errors.New("the file must be up to 1MB and list up to 1000 usernames")
+	ErrInvalidFollowImport = errors.New("the file must be a CSV with a username in the first column of every row")
+	ErrFollowImportRunning = errors.New("another follow import is running")
+	ErrFollowImportNotFound = errors.New("follow import not found")
+	ErrNotFollowing = errors.New("you are not following this user")
+	ErrPrivateAccount = errors.New("this account is private")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrEmptySearchQuery = errors.New("search query cannot be empty")
+	ErrPreconditionFailed = errors.New("the profile has been modified since it was fetched")
 )
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/config"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/repository"
+	"github.com/BloggingApp/user-service/internal/repository/postgres"
+	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	FOLLOW_COUNTS_FLUSH_BATCH_SIZE = 500
+	FOLLOW_COUNTS_RECONCILE_BATCH_SIZE = 1000
+	// Every drift is logged but only this many are kept in the report
+	MAX_REPORTED_FOLLOW_COUNT_DRIFTS = 100
+	// How long a reconciliation batch waits for a flush to release the lock
+	FOLLOW_COUNTS_LOCK_RETRY_INTERVAL = time.Second
+	// Used when the intervals are missing from the config
+	DEFAULT_FOLLOW_COUNTS_FLUSH_INTERVAL = time.Second * 5
+	DEFAULT_FOLLOW_COUNTS_RECONCILE_INTERVAL = time.Hour * 6
+)
+
+type followCountsService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	cfg config.FollowCountsConfig
+	running atomic.Bool
+}
+
+func newFollowCountsService(logger *zap.Logger, repo *repository.Repository, cfg config.FollowCountsConfig) FollowCounts {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DEFAULT_FOLLOW_COUNTS_FLUSH_INTERVAL
+	}
+	if cfg.ReconcileInterval <= 0 {
+		cfg.ReconcileInterval = DEFAULT_FOLLOW_COUNTS_RECONCILE_INTERVAL
+	}
+
+	return &followCountsService{
+		logger: logger,
+		repo: repo,
+		cfg: cfg,
+	}
+}
+
+func (s *followCountsService) Run(ctx context.Context) {
+	flushTicker := time.NewTicker(s.cfg.FlushInterval)
+	defer flushTicker.Stop()
+
+	reconcileTicker := time.NewTicker(s.cfg.ReconcileInterval)
+	defer reconcileTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-reconcileTicker.C:
+			if s.running.CompareAndSwap(false, true) {
+				go s.reconcile(ctx)
+			}
+		}
+	}
+}
+
+// flush adds the count deltas collected in redis to postgres, one update per
+// dirty user however many follows it got since the last flush. The flush is
+// skipped while another instance flushes or reconciles, the deltas wait for the
+// next one.
+func (s *followCountsService) flush(ctx context.Context) {
+	for ctx.Err() == nil {
+		var (
+			popped []string
+			taken map[string]model.FollowCountDeltas
+		)
+		usernames, err := s.repo.Postgres.User.AddFollowCounts(ctx, func(ctx context.Context) (map[uuid.UUID]model.FollowCountDeltas, error) {
+			userIDs, err := s.repo.Redis.Counters.PopDirtyFollowCounts(ctx, FOLLOW_COUNTS_FLUSH_BATCH_SIZE)
+			if err != nil {
+				return nil, err
+			}
+			popped = userIDs
+
+			taken = make(map[string]model.FollowCountDeltas, len(userIDs))
+			deltas := make(map[uuid.UUID]model.FollowCountDeltas, len(userIDs))
+			for _, rawUserID := range userIDs {
+				userID, err := uuid.Parse(rawUserID)
+				if err != nil {
+					continue
+				}
+
+				userDeltas, err := s.repo.Redis.Counters.TakeFollowCounts(ctx, rawUserID)
+				if err != nil {
+					return nil, err
+				}
+
+				if userDeltas.Followers == 0 && userDeltas.Follows == 0 {
+					continue
+				}
+
+				taken[rawUserID] = userDeltas
+				deltas[userID] = userDeltas
+			}
+
+			return deltas, nil
+		})
+		if err != nil {
+			if err == postgres.ErrLocked {
+				return
+			}
+
+			s.logger.Sugar().Errorf("failed to flush follow count deltas to postgres: %s", err.Error())
+
+			// Put the deltas back for the next flush, the users popped before the
+			// failure but not taken yet still have theirs in redis
+			if len(taken) > 0 {
+				if err := s.repo.Redis.Counters.IncrFollowCounts(ctx, taken); err != nil {
+					s.logger.Sugar().Errorf("failed to restore follow count deltas in redis: %s", err.Error())
+				}
+			}
+			if len(popped) > 0 {
+				if err := s.repo.Redis.Counters.MarkFollowCountsDirty(ctx, popped); err != nil {
+					s.logger.Sugar().Errorf("failed to mark follow counts dirty in redis: %s", err.Error())
+				}
+			}
+			return
+		}
+
+		for userID, username := range usernames {
+			s.deleteUserCache(ctx, userID, username)
+		}
+
+		if len(popped) < FOLLOW_COUNTS_FLUSH_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+// deleteUserCache drops the cached profiles after their counts changed in postgres.
+// The flush generation is bumped first, so the reads that loaded the counts before
+// the change don't cache them again.
+func (s *followCountsService) deleteUserCache(ctx context.Context, userID uuid.UUID, username string) {
+	if err := s.repo.Redis.Counters.BumpFlushGeneration(ctx); err != nil {
+		s.logger.Sugar().Errorf("failed to bump follow counts flush generation in redis: %s", err.Error())
+	}
+
+	if err := s.repo.Redis.Default.Del(
+		ctx,
+		redisrepo.UserKey(userID.String()),
+		redisrepo.UserByUsernameKey(username),
+	).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete user(%s) cache: %s", userID.String(), err.Error())
+	}
+}
+
+// pendingFollowCounts finds the deltas that the stored counts miss.
+func (s *followCountsService) pendingFollowCounts(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.FollowCountDeltas, error) {
+	rawUserIDs := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		rawUserIDs[i] = userID.String()
+	}
+
+	rawPending, err := s.repo.Redis.Counters.PendingFollowCounts(ctx, rawUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[uuid.UUID]model.FollowCountDeltas, len(userIDs))
+	for _, userID := range userIDs {
+		pending[userID] = rawPending[userID.String()]
+	}
+
+	return pending, nil
+}
+
+// StartReconciliation reconciles the follow counts in the background, the report
+// is available from LastReconciliation.
+func (s *followCountsService) StartReconciliation(ctx context.Context) error {
+	if !s.running.CompareAndSwap(false, true) {
+		return ErrReconciliationRunning
+	}
+
+	go s.reconcile(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+func (s *followCountsService) LastReconciliation(ctx context.Context) (*model.FollowCountsReconciliation, error) {
+	report, err := redisrepo.Get[model.FollowCountsReconciliation](s.repo.Redis.Default, ctx, redisrepo.FOLLOW_COUNTS_RECONCILIATION_KEY)
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrNoReconciliation
+		}
+
+		s.logger.Sugar().Errorf("failed to get follow counts reconciliation from redis: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return report, nil
+}
+
+// reconcile walks all users in batches, fixing the counts that drifted from the
+// followers table. The report is saved after every batch so the progress is visible.
+func (s *followCountsService) reconcile(ctx context.Context) {
+	defer s.running.Store(false)
+
+	report := &model.FollowCountsReconciliation{
+		StartedAt: time.Now(),
+		Drifts: []*model.FollowCountDrift{},
+	}
+	s.saveReport(ctx, report)
+
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		userIDs, drifts, err := s.repo.Postgres.User.ReconcileFollowCounts(ctx, after, FOLLOW_COUNTS_RECONCILE_BATCH_SIZE, s.pendingFollowCounts)
+		if err == postgres.ErrLocked {
+			select {
+			case <-ctx.Done():
+			case <-time.After(FOLLOW_COUNTS_LOCK_RETRY_INTERVAL):
+			}
+			continue
+		}
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to reconcile follow counts in postgres: %s", err.Error())
+			errMessage := err.Error()
+			report.Error = &errMessage
+			break
+		}
+
+		if len(userIDs) == 0 {
+			break
+		}
+
+		report.Scanned += int64(len(userIDs))
+		report.Fixed += int64(len(drifts))
+
+		for _, drift := range drifts {
+			s.logger.Sugar().Warnf(
+				"fixed user(%s) follow counts: followers %d -> %d, follows %d -> %d",
+				drift.UserID.String(),
+				drift.Followers,
+				drift.ActualFollowers,
+				drift.Follows,
+				drift.ActualFollows,
+			)
+
+			if len(report.Drifts) < MAX_REPORTED_FOLLOW_COUNT_DRIFTS {
+				report.Drifts = append(report.Drifts, drift)
+			}
+
+			s.deleteUserCache(ctx, drift.UserID, drift.Username)
+		}
+
+		s.saveReport(ctx, report)
+
+		after = userIDs[len(userIDs)-1]
+	}
+
+	finishedAt := time.Now()
+	report.FinishedAt = &finishedAt
+	s.saveReport(ctx, report)
+
+	s.logger.Sugar().Infof("reconciled follow counts of %d users, fixed %d", report.Scanned, report.Fixed)
+}
+
+func (s *followCountsService) saveReport(ctx context.Context, report *model.FollowCountsReconciliation) {
+	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.FOLLOW_COUNTS_RECONCILIATION_KEY, report, 0); err != nil {
+		s.logger.Sugar().Errorf("failed to set follow counts reconciliation in redis: %s", err.Error())
+	}
+}
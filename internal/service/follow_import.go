@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	MAX_FOLLOW_IMPORT_SIZE = 1 << 20
+	MAX_FOLLOW_IMPORT_ROWS = 1000
+	// Follows are created at most this often so that an import doesn't flood the followed users
+	FOLLOW_IMPORT_INTERVAL = time.Second / 5
+	// The import's progress is saved to redis after every this many rows
+	FOLLOW_IMPORT_SAVE_EVERY = 10
+	FOLLOW_IMPORT_TTL = time.Hour * 24
+	// The active import key is a heartbeat refreshed with every save, an import
+	// whose heartbeat expired is failed
+	FOLLOW_IMPORT_HEARTBEAT_TTL = time.Second * 30
+	FOLLOWS_EXPORT_BATCH_SIZE = 50
+)
+
+type followImportRow struct {
+	row int
+	username string
+}
+
+// ImportFollows follows the users listed in the first column of the CSV in the
+// background, its progress is available from FindFollowImport. A header row named
+// "username" and @ before usernames are allowed. A user can run one import at a time,
+// the one of a crashed instance stops blocking new ones when its heartbeat expires.
+func (s *userService) ImportFollows(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) (*model.FollowImport, error) {
+	if fileHeader.Size > MAX_FOLLOW_IMPORT_SIZE {
+		return nil, ErrFollowImportTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, ErrInvalidFollowImport
+	}
+	defer file.Close()
+
+	rows, err := parseFollowImport(io.LimitReader(file, MAX_FOLLOW_IMPORT_SIZE))
+	if err != nil {
+		return nil, err
+	}
+
+	followImport := &model.FollowImport{
+		ID: uuid.New(),
+		UserID: user.ID,
+		Status: model.FOLLOW_IMPORT_STATUS_PENDING,
+		Total: len(rows),
+		Results: []*model.FollowImportResult{},
+		CreatedAt: time.Now(),
+	}
+
+	started, err := s.repo.Redis.Default.SetNX(ctx, redisrepo.ActiveFollowImportKey(user.ID.String()), followImport.ID.String(), FOLLOW_IMPORT_HEARTBEAT_TTL)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) active follow import in redis: %s", user.ID.String(), err.Error())
+		return nil, ErrInternal
+	}
+	if !started {
+		return nil, ErrFollowImportRunning
+	}
+
+	if err := s.saveFollowImport(ctx, followImport); err != nil {
+		return nil, err
+	}
+
+	go s.runFollowImport(context.WithoutCancel(ctx), *followImport, rows)
+
+	return followImport, nil
+}
+
+func parseFollowImport(file io.Reader) ([]followImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []followImportRow
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidFollowImport
+		}
+
+		username := strings.TrimPrefix(strings.TrimSpace(record[0]), "@")
+		if username == "" || (row == 1 && strings.EqualFold(username, "username")) {
+			continue
+		}
+
+		if len(rows) == MAX_FOLLOW_IMPORT_ROWS {
+			return nil, ErrFollowImportTooLarge
+		}
+
+		rows = append(rows, followImportRow{row: row, username: username})
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrInvalidFollowImport
+	}
+
+	return rows, nil
+}
+
+func (s *userService) runFollowImport(ctx context.Context, followImport model.FollowImport, rows []followImportRow) {
+	defer func() {
+		// Once the heartbeat has expired the key may belong to another import already
+		if _, err := s.repo.Redis.Default.DelIfEquals(ctx, redisrepo.ActiveFollowImportKey(followImport.UserID.String()), followImport.ID.String()); err != nil {
+			s.logger.Sugar().Errorf("failed to delete user(%s) active follow import from redis: %s", followImport.UserID.String(), err.Error())
+		}
+	}()
+
+	followImport.Status = model.FOLLOW_IMPORT_STATUS_RUNNING
+	if !s.heartbeatFollowImport(ctx, &followImport) {
+		return
+	}
+
+	ticker := time.NewTicker(FOLLOW_IMPORT_INTERVAL)
+	defer ticker.Stop()
+
+	for i, row := range rows {
+		<-ticker.C
+
+		followImport.Results = append(followImport.Results, &model.FollowImportResult{
+			Row: row.row,
+			Username: row.username,
+			Result: s.importFollow(ctx, followImport.UserID, row.username),
+		})
+		followImport.Processed++
+
+		if (i + 1) % FOLLOW_IMPORT_SAVE_EVERY == 0 && !s.heartbeatFollowImport(ctx, &followImport) {
+			return
+		}
+	}
+
+	finishedAt := time.Now()
+	followImport.Status = model.FOLLOW_IMPORT_STATUS_DONE
+	followImport.FinishedAt = &finishedAt
+	s.saveFollowImport(ctx, &followImport)
+}
+
+// heartbeatFollowImport saves the import's progress and refreshes its heartbeat. It
+// reports false when the heartbeat has already expired, the import is failed then
+// and must stop, since it doesn't block another import anymore.
+func (s *userService) heartbeatFollowImport(ctx context.Context, followImport *model.FollowImport) bool {
+	s.saveFollowImport(ctx, followImport)
+
+	alive, err := s.repo.Redis.Default.ExpireIfEquals(ctx, redisrepo.ActiveFollowImportKey(followImport.UserID.String()), followImport.ID.String(), FOLLOW_IMPORT_HEARTBEAT_TTL)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to refresh user(%s) active follow import in redis: %s", followImport.UserID.String(), err.Error())
+		return true
+	}
+
+	if !alive {
+		s.logger.Sugar().Warnf("follow import(%s) heartbeat expired, stopping it", followImport.ID.String())
+
+		finishedAt := time.Now()
+		followImport.Status = model.FOLLOW_IMPORT_STATUS_FAILED
+		followImport.FinishedAt = &finishedAt
+		s.saveFollowImport(ctx, followImport)
+	}
+
+	return alive
+}
+
+func (s *userService) importFollow(ctx context.Context, followerID uuid.UUID, username string) string {
+	user, err := s.repo.Postgres.User.FindByUsername(ctx, normalizeUsername(username))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return model.FOLLOW_IMPORT_RESULT_NOT_FOUND
+		}
+
+		s.logger.Sugar().Errorf("failed to get user(%s) to import follow from postgres: %s", username, err.Error())
+		return model.FOLLOW_IMPORT_RESULT_FAILED
+	}
+
+	if user.ID == followerID {
+		return model.FOLLOW_IMPORT_RESULT_YOURSELF
+	}
+
+	requested, err := s.Follow(ctx, model.Follower{UserID: user.ID, FollowerID: followerID})
+	switch err {
+	case nil:
+		if requested {
+			return model.FOLLOW_IMPORT_RESULT_REQUESTED
+		}
+		return model.FOLLOW_IMPORT_RESULT_FOLLOWED
+	case ErrAlreadyFollowing:
+		return model.FOLLOW_IMPORT_RESULT_ALREADY_FOLLOWING
+	case ErrFollowBlocked:
+		return model.FOLLOW_IMPORT_RESULT_BLOCKED
+	case ErrUserNotFound:
+		return model.FOLLOW_IMPORT_RESULT_NOT_FOUND
+	default:
+		return model.FOLLOW_IMPORT_RESULT_FAILED
+	}
+}
+
+func (s *userService) saveFollowImport(ctx context.Context, followImport *model.FollowImport) error {
+	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.FollowImportKey(followImport.ID.String()), followImport, FOLLOW_IMPORT_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set follow import(%s) in redis: %s", followImport.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// FindFollowImport gets the user's import, imports of other users are not found.
+// An unfinished import whose heartbeat expired is reported as failed.
+func (s *userService) FindFollowImport(ctx context.Context, userID uuid.UUID, importID uuid.UUID) (*model.FollowImport, error) {
+	// The heartbeat is read before the import, which is saved as done before the heartbeat is deleted
+	activeImportID, err := s.repo.Redis.Default.Get(ctx, redisrepo.ActiveFollowImportKey(userID.String())).Result()
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) active follow import from redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	followImport, err := redisrepo.Get[model.FollowImport](s.repo.Redis.Default, ctx, redisrepo.FollowImportKey(importID.String()))
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrFollowImportNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to get follow import(%s) from redis: %s", importID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	if followImport.UserID != userID {
+		return nil, ErrFollowImportNotFound
+	}
+
+	if followImport.FinishedAt == nil && activeImportID != importID.String() {
+		followImport.Status = model.FOLLOW_IMPORT_STATUS_FAILED
+	}
+
+	return followImport, nil
+}
+
+// ExportFollowers passes all of the user's followers to write, newest first, a page at a time.
+func (s *userService) ExportFollowers(ctx context.Context, userID uuid.UUID, write func(follows []*model.FullFollower) error) error {
+	return s.exportFollows(ctx, userID, s.repo.Postgres.User.FindUserFollowers, write)
+}
+
+// ExportFollows is ExportFollowers for the users the user follows.
+func (s *userService) ExportFollows(ctx context.Context, userID uuid.UUID, write func(follows []*model.FullFollower) error) error {
+	return s.exportFollows(ctx, userID, s.repo.Postgres.User.FindUserFollows, write)
+}
+
+func (s *userService) exportFollows(ctx context.Context, userID uuid.UUID, find findFollowsFunc, write func(follows []*model.FullFollower) error) error {
+	var after *model.FollowCursor
+	for {
+		follows, err := find(ctx, userID, after, FOLLOWS_EXPORT_BATCH_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user(%s) follows to export from postgres: %s", userID.String(), err.Error())
+			return ErrInternal
+		}
+
+		if len(follows) > 0 {
+			if err := write(follows); err != nil {
+				return err
+			}
+		}
+
+		if len(follows) < FOLLOWS_EXPORT_BATCH_SIZE {
+			return nil
+		}
+
+		cursor := follows[len(follows)-1].Cursor()
+		after = &cursor
+	}
+}
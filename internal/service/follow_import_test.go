@@ -0,0 +1,63 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFollowImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []followImportRow
+		wantErr error
+	}{
+		{
+			name: "usernames",
+			file: "alice\nbob\n",
+			want: []followImportRow{{row: 1, username: "alice"}, {row: 2, username: "bob"}},
+		},
+		{
+			name: "header, extra columns and at signs",
+			file: "Username,display_name\n@alice,Alice\n  bob , Bob\n",
+			want: []followImportRow{{row: 2, username: "alice"}, {row: 3, username: "bob"}},
+		},
+		{
+			name: "username past the first row isn't a header",
+			file: "alice\nusername\n",
+			want: []followImportRow{{row: 1, username: "alice"}, {row: 2, username: "username"}},
+		},
+		{
+			name: "empty rows skipped",
+			file: "alice\n,Bob\n\"\"\ncarol\r\n",
+			want: []followImportRow{{row: 1, username: "alice"}, {row: 4, username: "carol"}},
+		},
+		{name: "header only", file: "username\n", wantErr: ErrInvalidFollowImport},
+		{name: "empty", file: "", wantErr: ErrInvalidFollowImport},
+		{name: "not a csv", file: "alice\n\"bob\n", wantErr: ErrInvalidFollowImport},
+		{name: "too many rows", file: strings.Repeat("alice\n", MAX_FOLLOW_IMPORT_ROWS + 1), wantErr: ErrFollowImportTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFollowImport(strings.NewReader(tt.file))
+			if err != tt.wantErr {
+				t.Fatalf("parseFollowImport() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFollowImport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFollowImportRowLimit(t *testing.T) {
+	rows, err := parseFollowImport(strings.NewReader("username\n" + strings.Repeat("alice\n", MAX_FOLLOW_IMPORT_ROWS)))
+	if err != nil {
+		t.Fatalf("parseFollowImport() error = %v", err)
+	}
+	if len(rows) != MAX_FOLLOW_IMPORT_ROWS {
+		t.Errorf("len(parseFollowImport()) = %d, want %d", len(rows), MAX_FOLLOW_IMPORT_ROWS)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+)
+
+const (
+	IDENTICON_GRID_SIZE = 5
+	IDENTICON_SIZE = 512
+)
+
+var identiconBackground = color.NRGBA{R: 240, G: 240, B: 240, A: 255}
+
+// generateIdenticon draws a horizontally symmetric 5x5 pattern picked by the
+// user's ID in a color picked by their name, so the same inputs always give
+// the same avatar.
+func generateIdenticon(userID uuid.UUID, name string) image.Image {
+	sum := sha256.Sum256(userID[:])
+
+	img := image.NewNRGBA(image.Rect(0, 0, IDENTICON_SIZE, IDENTICON_SIZE))
+	draw.Draw(img, img.Bounds(), image.NewUniform(identiconBackground), image.Point{}, draw.Src)
+
+	cellSize := IDENTICON_SIZE / (IDENTICON_GRID_SIZE + 1)
+	margin := (IDENTICON_SIZE - cellSize * IDENTICON_GRID_SIZE) / 2
+	foreground := image.NewUniform(identiconColor(name))
+
+	columns := (IDENTICON_GRID_SIZE + 1) / 2
+	for row := 0; row < IDENTICON_GRID_SIZE; row++ {
+		for column := 0; column < columns; column++ {
+			if sum[row * columns + column] % 2 == 0 {
+				continue
+			}
+
+			for _, x := range []int{column, IDENTICON_GRID_SIZE - 1 - column} {
+				cell := image.Rect(0, 0, cellSize, cellSize).Add(image.Pt(margin + x * cellSize, margin + row * cellSize))
+				draw.Draw(img, cell, foreground, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return img
+}
+
+func identiconColor(name string) color.NRGBA {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	hue := float64(h.Sum32() % 360)
+
+	return hslToRGB(hue, 0.55, 0.5)
+}
+
+func hslToRGB(hue, saturation, lightness float64) color.NRGBA {
+	chroma := (1 - math.Abs(2 * lightness - 1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue / 60, 2) - 1))
+	m := lightness - chroma / 2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return color.NRGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 255,
+	}
+}
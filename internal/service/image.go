@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"mime/multipart"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/h2non/filetype"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	MAX_IMAGE_UPLOAD_SIZE = 5 << 20
+	MAX_IMAGE_DIMENSION = 4096
+)
+
+const (
+	BANNER_WIDTH = 1500
+	BANNER_HEIGHT = 500
+	BANNER_ASPECT_RATIO_TOLERANCE = 0.15
+)
+
+// AVATAR_SIZES are the square sizes every avatar is stored in, the last one is the largest.
+var AVATAR_SIZES = []int{64, 128, 512}
+
+// decodeImageUpload checks the upload's size and dimensions before decoding it.
+// Decoding and re-encoding the image drops EXIF, GPS and any other metadata, so a
+// JPEG's EXIF orientation is applied to the pixels, before any crop rectangle that
+// the client picked on the image as it is displayed.
+func decodeImageUpload(fileHeader *multipart.FileHeader) (image.Image, error) {
+	if fileHeader.Size > MAX_IMAGE_UPLOAD_SIZE {
+		return nil, ErrImageTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buff := make([]byte, 512)
+	if _, err := file.Read(buff); err != nil {
+		if err == io.EOF {
+			return nil, ErrFileMustBeImage
+		}
+		return nil, err
+	}
+
+	if !filetype.IsImage(buff) {
+		return nil, ErrFileMustBeImage
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(io.LimitReader(file, MAX_IMAGE_UPLOAD_SIZE))
+	if err != nil {
+		return nil, ErrFileMustBeImage
+	}
+	if config.Width > MAX_IMAGE_DIMENSION || config.Height > MAX_IMAGE_DIMENSION {
+		return nil, ErrImageDimensionsTooLarge
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(io.LimitReader(file, MAX_IMAGE_UPLOAD_SIZE))
+	if err != nil {
+		return nil, ErrFileMustBeImage
+	}
+
+	if format != "jpeg" {
+		return img, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return orientImage(img, jpegOrientation(io.LimitReader(file, MAX_IMAGE_UPLOAD_SIZE))), nil
+}
+
+// jpegOrientation reads the orientation tag from the JPEG's EXIF segment, it
+// returns 1 (stored as displayed) when there is none.
+func jpegOrientation(r io.Reader) int {
+	reader := bufio.NewReader(r)
+
+	var soi [2]byte
+	if _, err := io.ReadFull(reader, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+
+	for {
+		var marker [4]byte
+		if _, err := io.ReadFull(reader, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+
+		// The EXIF segment comes before the image data
+		if marker[1] == 0xDA || marker[1] == 0xD9 {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if size < 0 {
+			return 1
+		}
+
+		if marker[1] != 0xE1 {
+			if _, err := reader.Discard(size); err != nil {
+				return 1
+			}
+			continue
+		}
+
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(reader, segment); err != nil {
+			return 1
+		}
+
+		if orientation := exifOrientation(segment); orientation != 0 {
+			return orientation
+		}
+	}
+}
+
+// exifOrientation finds the orientation tag in IFD0 of an APP1 segment, 0 when
+// the segment isn't EXIF or has no valid orientation.
+func exifOrientation(segment []byte) int {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := segment[6:]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int64(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd + 2 > int64(len(tiff)) {
+		return 0
+	}
+
+	entries := int64(order.Uint16(tiff[ifd:]))
+	for i := int64(0); i < entries; i++ {
+		entry := ifd + 2 + i * 12
+		if entry + 12 > int64(len(tiff)) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry + 8:]))
+			if orientation < 1 || orientation > 8 {
+				return 0
+			}
+			return orientation
+		}
+	}
+
+	return 0
+}
+
+// orientImage turns img as the EXIF orientation says it is displayed.
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if orientation >= 5 {
+		dst = image.NewNRGBA(image.Rect(0, 0, height, width))
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = width - 1 - x, y
+			case 3: // rotated 180°
+				dx, dy = width - 1 - x, height - 1 - y
+			case 4: // mirrored vertically
+				dx, dy = x, height - 1 - y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise to display
+				dx, dy = height - 1 - y, x
+			case 7: // transversed
+				dx, dy = height - 1 - y, width - 1 - x
+			case 8: // rotated 90° counterclockwise to display
+				dx, dy = y, width - 1 - x
+			}
+
+			dst.Set(dx, dy, img.At(bounds.Min.X + x, bounds.Min.Y + y))
+		}
+	}
+
+	return dst
+}
+
+// cropSquare cuts the client-supplied crop rectangle out of img, or its centered
+// square when no rectangle was supplied.
+func cropSquare(img image.Image, crop dto.AvatarCropReq) (image.Image, error) {
+	bounds := img.Bounds()
+
+	var rect image.Rectangle
+	if crop.X == nil && crop.Y == nil && crop.Size == nil {
+		size := min(bounds.Dx(), bounds.Dy())
+		x := bounds.Min.X + (bounds.Dx() - size) / 2
+		y := bounds.Min.Y + (bounds.Dy() - size) / 2
+		rect = image.Rect(x, y, x + size, y + size)
+	} else {
+		if crop.X == nil || crop.Y == nil || crop.Size == nil {
+			return nil, ErrInvalidCropRect
+		}
+
+		x := bounds.Min.X + *crop.X
+		y := bounds.Min.Y + *crop.Y
+		rect = image.Rect(x, y, x + *crop.Size, y + *crop.Size)
+		if rect.Empty() || !rect.In(bounds) {
+			return nil, ErrInvalidCropRect
+		}
+	}
+
+	return subImage(img, rect), nil
+}
+
+// cropBanner accepts images whose aspect ratio is close to the banner's and
+// trims the centered excess so that it matches exactly.
+func cropBanner(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	target := float64(BANNER_WIDTH) / float64(BANNER_HEIGHT)
+	ratio := float64(bounds.Dx()) / float64(bounds.Dy())
+	if math.Abs(ratio - target) / target > BANNER_ASPECT_RATIO_TOLERANCE {
+		return nil, ErrInvalidBannerAspectRatio
+	}
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if ratio > target {
+		width = int(math.Round(float64(height) * target))
+	} else {
+		height = int(math.Round(float64(width) / target))
+	}
+
+	x := bounds.Min.X + (bounds.Dx() - width) / 2
+	y := bounds.Min.Y + (bounds.Dy() - height) / 2
+
+	return subImage(img, image.Rect(x, y, x + width, y + height)), nil
+}
+
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+func resizeImage(img image.Image, width, height int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, img); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buff bytes.Buffer
+	if err := jpeg.Encode(&buff, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+)
+
+// exifSegment is an APP1 segment body with the orientation tag as the second IFD0 entry.
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8 + 2 + 12 * 2 + 4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 2)
+
+	// ImageWidth, then Orientation
+	order.PutUint16(tiff[10:], 0x0100)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], 640)
+	order.PutUint16(tiff[22:], 0x0112)
+	order.PutUint16(tiff[24:], 3)
+	order.PutUint32(tiff[26:], 1)
+	order.PutUint16(tiff[30:], orientation)
+
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+func jpegSegment(marker byte, body []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(body) + 2))
+	return append(segment, body...)
+}
+
+func jpegWithSegments(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	return append(data, jpegSegment(0xDA, []byte{0, 0, 0})...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment []byte
+		want    int
+	}{
+		{name: "little endian", segment: exifSegment(binary.LittleEndian, 6), want: 6},
+		{name: "big endian", segment: exifSegment(binary.BigEndian, 8), want: 8},
+		{name: "out of range", segment: exifSegment(binary.LittleEndian, 9), want: 0},
+		{name: "zero", segment: exifSegment(binary.BigEndian, 0), want: 0},
+		{name: "not exif", segment: append([]byte("http://ns.adobe.com/xap/1.0/\x00"), make([]byte, 32)...), want: 0},
+		{name: "unknown byte order", segment: append([]byte("Exif\x00\x00XX"), make([]byte, 32)...), want: 0},
+		{name: "truncated", segment: exifSegment(binary.LittleEndian, 6)[:30], want: 0},
+		{name: "too short", segment: []byte("Exif\x00\x00II"), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.segment); got != tt.want {
+				t.Errorf("exifOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	jfif := jpegSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	xmp := jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+	exif := jpegSegment(0xE1, exifSegment(binary.BigEndian, 3))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "exif after jfif", data: jpegWithSegments(jfif, exif), want: 3},
+		{name: "exif after xmp", data: jpegWithSegments(xmp, exif), want: 3},
+		{name: "no exif", data: jpegWithSegments(jfif), want: 1},
+		{name: "exif after the image data", data: append(jpegWithSegments(jfif), exif...), want: 1},
+		{name: "truncated segment", data: jpegWithSegments(jfif, exif)[:len(jfif) + 10], want: 1},
+		{name: "not a jpeg", data: []byte("\x89PNG\r\n\x1a\n"), want: 1},
+		{name: "empty", data: nil, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(bytes.NewReader(tt.data)); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+var testMarker = color.NRGBA{R: 255, A: 255}
+
+// markedImage is a transparent image with the marker color at (x, y).
+func markedImage(rect image.Rectangle, x, y int) *image.NRGBA {
+	img := image.NewNRGBA(rect)
+	img.Set(x, y, testMarker)
+	return img
+}
+
+func TestOrientImage(t *testing.T) {
+	// A 2x3 image with its top left pixel marked
+	src := markedImage(image.Rect(10, 20, 12, 23), 10, 20)
+
+	tests := []struct {
+		orientation int
+		wantSize    image.Point
+		wantMarker  image.Point
+	}{
+		{orientation: 0, wantSize: image.Pt(2, 3), wantMarker: image.Pt(10, 20)},
+		{orientation: 1, wantSize: image.Pt(2, 3), wantMarker: image.Pt(10, 20)},
+		{orientation: 2, wantSize: image.Pt(2, 3), wantMarker: image.Pt(1, 0)},
+		{orientation: 3, wantSize: image.Pt(2, 3), wantMarker: image.Pt(1, 2)},
+		{orientation: 4, wantSize: image.Pt(2, 3), wantMarker: image.Pt(0, 2)},
+		{orientation: 5, wantSize: image.Pt(3, 2), wantMarker: image.Pt(0, 0)},
+		{orientation: 6, wantSize: image.Pt(3, 2), wantMarker: image.Pt(2, 0)},
+		{orientation: 7, wantSize: image.Pt(3, 2), wantMarker: image.Pt(2, 1)},
+		{orientation: 8, wantSize: image.Pt(3, 2), wantMarker: image.Pt(0, 1)},
+		{orientation: 9, wantSize: image.Pt(2, 3), wantMarker: image.Pt(10, 20)},
+	}
+
+	for _, tt := range tests {
+		got := orientImage(src, tt.orientation)
+
+		if got.Bounds().Size() != tt.wantSize {
+			t.Errorf("orientImage(%d) size = %v, want %v", tt.orientation, got.Bounds().Size(), tt.wantSize)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(got.At(tt.wantMarker.X, tt.wantMarker.Y)); c != testMarker {
+			t.Errorf("orientImage(%d) at %v = %v, want the marker", tt.orientation, tt.wantMarker, c)
+		}
+	}
+}
+
+func TestCropSquare(t *testing.T) {
+	// 100x60, the centered square starts at x = 20
+	src := markedImage(image.Rect(0, 0, 100, 60), 20, 0)
+
+	tests := []struct {
+		name       string
+		crop       dto.AvatarCropReq
+		wantSize   int
+		wantMarker bool
+		wantErr    error
+	}{
+		{name: "centered", crop: dto.AvatarCropReq{}, wantSize: 60, wantMarker: true},
+		{name: "rect", crop: dto.AvatarCropReq{X: ptr(20), Y: ptr(0), Size: ptr(30)}, wantSize: 30, wantMarker: true},
+		{name: "other rect", crop: dto.AvatarCropReq{X: ptr(40), Y: ptr(30), Size: ptr(30)}, wantSize: 30},
+		{name: "whole height", crop: dto.AvatarCropReq{X: ptr(40), Y: ptr(0), Size: ptr(60)}, wantSize: 60},
+		{name: "partial", crop: dto.AvatarCropReq{X: ptr(0), Size: ptr(30)}, wantErr: ErrInvalidCropRect},
+		{name: "out of bounds", crop: dto.AvatarCropReq{X: ptr(80), Y: ptr(0), Size: ptr(30)}, wantErr: ErrInvalidCropRect},
+		{name: "too large", crop: dto.AvatarCropReq{X: ptr(0), Y: ptr(0), Size: ptr(61)}, wantErr: ErrInvalidCropRect},
+		{name: "empty", crop: dto.AvatarCropReq{X: ptr(0), Y: ptr(0), Size: ptr(0)}, wantErr: ErrInvalidCropRect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cropSquare(src, tt.crop)
+			if err != tt.wantErr {
+				t.Fatalf("cropSquare() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Bounds() != image.Rect(0, 0, tt.wantSize, tt.wantSize) {
+				t.Errorf("cropSquare() bounds = %v, want %dx%d", got.Bounds(), tt.wantSize, tt.wantSize)
+			}
+			if marked := color.NRGBAModel.Convert(got.At(0, 0)) == testMarker; marked != tt.wantMarker {
+				t.Errorf("cropSquare() marker at (0, 0) = %v, want %v", marked, tt.wantMarker)
+			}
+		})
+	}
+}
+
+func TestCropBanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		wantSize image.Point
+		wantErr  error
+	}{
+		{name: "exact", width: 1500, height: 500, wantSize: image.Pt(1500, 500)},
+		{name: "scaled", width: 750, height: 250, wantSize: image.Pt(750, 250)},
+		{name: "slightly wider", width: 1600, height: 500, wantSize: image.Pt(1500, 500)},
+		{name: "slightly taller", width: 1500, height: 560, wantSize: image.Pt(1500, 500)},
+		{name: "square", width: 1000, height: 1000, wantErr: ErrInvalidBannerAspectRatio},
+		{name: "too wide", width: 2000, height: 500, wantErr: ErrInvalidBannerAspectRatio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cropBanner(image.NewNRGBA(image.Rect(0, 0, tt.width, tt.height)))
+			if err != tt.wantErr {
+				t.Fatalf("cropBanner() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Bounds().Size() != tt.wantSize {
+				t.Errorf("cropBanner() size = %v, want %v", got.Bounds().Size(), tt.wantSize)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
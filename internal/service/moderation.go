@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/dto"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/rabbitmq"
+	"github.com/BloggingApp/user-service/internal/repository"
+	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	MAX_REPORT_TEXT_LENGTH = 1000
+	MAX_MODERATOR_NOTE_LENGTH = 1000
+)
+
+type moderationService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	rabbitmq *rabbitmq.MQConn
+	userService User
+}
+
+func newModerationService(logger *zap.Logger, repo *repository.Repository, rabbitmq *rabbitmq.MQConn, userService User) Moderation {
+	return &moderationService{
+		logger: logger,
+		repo: repo,
+		rabbitmq: rabbitmq,
+		userService: userService,
+	}
+}
+
+// Report files a report, moderators are notified only when the report is new
+// or reopened, not when the reporter merely updates an open one.
+func (s *moderationService) Report(ctx context.Context, reporter model.FullUser, targetID uuid.UUID, req dto.ReportReq) error {
+	if reporter.ID == targetID {
+		return ErrReportYourself
+	}
+
+	text, reason := normalizeProfileText(req.Text, true, MAX_REPORT_TEXT_LENGTH)
+	if reason != "" {
+		return dto.FieldErrors{"text": reason}
+	}
+
+	if _, err := s.userService.FindByID(ctx, targetID); err != nil {
+		return err
+	}
+
+	report, previousStatus, err := s.repo.Postgres.Moderation.CreateReport(ctx, model.Report{
+		ReporterID: reporter.ID,
+		TargetID: targetID,
+		Category: req.Category,
+		Text: text,
+	})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to create report of user(%s) by user(%s) in postgres: %s", targetID.String(), reporter.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	if previousStatus != nil && isOpenReportStatus(*previousStatus) {
+		return nil
+	}
+
+	openReports, err := s.repo.Postgres.Moderation.CountOpenReports(ctx, targetID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to count open reports of user(%s) in postgres: %s", targetID.String(), err.Error())
+		return ErrInternal
+	}
+
+	bodyJSON, err := json.Marshal(dto.ReportEvent{
+		Version: dto.REPORT_EVENT_VERSION,
+		ReportID: report.ID,
+		ReporterID: report.ReporterID,
+		TargetID: report.TargetID,
+		Category: report.Category,
+		TargetOpenReports: openReports,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal report event to json: %s", err.Error())
+		return ErrInternal
+	}
+	if err := s.rabbitmq.PublishToQueue(rabbitmq.MODERATION_REPORTS_QUEUE, bodyJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish report to queue: %s", err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *moderationService) FindReports(ctx context.Context, filter model.ReportsFilter, limit int, offset int) ([]*model.FullReport, error) {
+	reports, err := s.repo.Postgres.Moderation.FindReports(ctx, filter, limit, offset)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get reports from postgres: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return reports, nil
+}
+
+// TriageReport marks an open report as taken by the moderator.
+func (s *moderationService) TriageReport(ctx context.Context, moderator model.FullUser, reportID uuid.UUID, req dto.TriageReportReq) error {
+	note, reason := normalizeProfileText(req.Note, true, MAX_MODERATOR_NOTE_LENGTH)
+	if reason != "" {
+		return dto.FieldErrors{"note": reason}
+	}
+
+	return s.updateReportStatus(ctx, moderator, reportID, []string{model.REPORT_STATUS_OPEN}, model.REPORT_STATUS_TRIAGED, note)
+}
+
+// ResolveReport closes an open or triaged report. Suspending the reported user
+// resolves all of the user's open reports at once.
+func (s *moderationService) ResolveReport(ctx context.Context, moderator model.FullUser, reportID uuid.UUID, req dto.ResolveReportReq) error {
+	note, reason := normalizeProfileText(req.Note, true, MAX_MODERATOR_NOTE_LENGTH)
+	if reason != "" {
+		return dto.FieldErrors{"note": reason}
+	}
+
+	if req.SuspendUntil == nil {
+		return s.updateReportStatus(ctx, moderator, reportID, []string{model.REPORT_STATUS_OPEN, model.REPORT_STATUS_TRIAGED}, req.Status, note)
+	}
+
+	if req.Status != model.REPORT_STATUS_RESOLVED {
+		return dto.FieldErrors{"suspend_until": "can only be set when the report is resolved"}
+	}
+	if !req.SuspendUntil.After(time.Now()) {
+		return dto.FieldErrors{"suspend_until": "must be in the future"}
+	}
+
+	report, err := s.findReport(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	if !isOpenReportStatus(report.Status) {
+		return ErrReportAlreadyClosed
+	}
+
+	target, err := s.userService.FindByID(ctx, report.TargetID)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := s.repo.Postgres.Moderation.SuspendUser(ctx, target.ID, *req.SuspendUntil, moderator.ID, note)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to suspend user(%s) in postgres: %s", target.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	s.logger.Sugar().Infof("user(%s) is suspended until %s by moderator(%s), %d reports resolved", target.ID.String(), req.SuspendUntil.String(), moderator.ID.String(), resolved)
+
+	return s.deleteUserCache(ctx, *target)
+}
+
+func (s *moderationService) LiftSuspension(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userService.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Postgres.Moderation.LiftSuspension(ctx, userID); err != nil {
+		s.logger.Sugar().Errorf("failed to lift user(%s) suspension in postgres: %s", userID.String(), err.Error())
+		return ErrInternal
+	}
+
+	return s.deleteUserCache(ctx, *user)
+}
+
+func (s *moderationService) updateReportStatus(ctx context.Context, moderator model.FullUser, reportID uuid.UUID, fromStatuses []string, status string, note *string) error {
+	if err := s.repo.Postgres.Moderation.UpdateReportStatus(ctx, reportID, fromStatuses, status, moderator.ID, note); err != nil {
+		if err != pgx.ErrNoRows {
+			s.logger.Sugar().Errorf("failed to update report(%s) status in postgres: %s", reportID.String(), err.Error())
+			return ErrInternal
+		}
+
+		// Tell a missing report from one that is no longer in a suitable status
+		if _, err := s.findReport(ctx, reportID); err != nil {
+			return err
+		}
+		return ErrInvalidReportTransition
+	}
+
+	return nil
+}
+
+func (s *moderationService) findReport(ctx context.Context, reportID uuid.UUID) (*model.Report, error) {
+	report, err := s.repo.Postgres.Moderation.FindReportByID(ctx, reportID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrReportNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to get report(%s) from postgres: %s", reportID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return report, nil
+}
+
+func (s *moderationService) deleteUserCache(ctx context.Context, user model.FullUser) error {
+	if err := s.repo.Redis.Default.Del(
+		ctx,
+		redisrepo.UserKey(user.ID.String()),
+		redisrepo.UserByUsernameKey(user.Username),
+	).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete user(%s) cache: %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func isOpenReportStatus(status string) bool {
+	return status == model.REPORT_STATUS_OPEN || status == model.REPORT_STATUS_TRIAGED
+}
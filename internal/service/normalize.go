@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	USERNAME_FORBIDDEN_CHARACTERS = " !@#№$;%^:&?*()-/\\|,<>`~+="
+	MIN_USERNAME_LENGTH = 3
+	MAX_USERNAME_LENGTH = 20
+	MAX_DISPLAY_NAME_LENGTH = 50
+	MAX_BIO_LENGTH = 300
+	MAX_SOCIAL_LINK_LABEL_LENGTH = 30
+)
+
+// normalizeText converts s to NFC, drops control characters (keeping
+// newlines when allowNewlines is set) and trims surrounding whitespace.
+func normalizeText(s string, allowNewlines bool) string {
+	s = norm.NFC.String(strings.ReplaceAll(s, "\r\n", "\n"))
+
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' && allowNewlines {
+			return r
+		}
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.TrimSpace(s)
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func validateUsername(username string) string {
+	length := utf8.RuneCountInString(username)
+	if length < MIN_USERNAME_LENGTH || length > MAX_USERNAME_LENGTH {
+		return fmt.Sprintf("must be between %d and %d characters long", MIN_USERNAME_LENGTH, MAX_USERNAME_LENGTH)
+	}
+	if strings.ContainsAny(username, USERNAME_FORBIDDEN_CHARACTERS) {
+		return ErrUsernameCannotContainSpecialCharacters.Error()
+	}
+	return ""
+}
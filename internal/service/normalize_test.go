@@ -0,0 +1,61 @@
+package service
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		allowNewlines bool
+		want          string
+	}{
+		{name: "trimmed", s: "  hello \t", want: "hello"},
+		{name: "nfc", s: "cafe\u0301", want: "caf\u00e9"},
+		{name: "control characters dropped", s: "he\x00l\x1blo\u0085", want: "hello"},
+		{name: "invalid utf8 dropped", s: "he\xffllo", want: "hello"},
+		{name: "newlines dropped", s: "one\ntwo\r\nthree", want: "onetwothree"},
+		{name: "newlines kept", s: "one\ntwo\r\nthree", allowNewlines: true, want: "one\ntwo\nthree"},
+		{name: "surrounding newlines trimmed", s: "\n\nbio\n", allowNewlines: true, want: "bio"},
+		{name: "empty", s: " \x00 ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeText(tt.s, tt.allowNewlines); got != tt.want {
+				t.Errorf("normalizeText(%q, %v) = %q, want %q", tt.s, tt.allowNewlines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	if got := normalizeUsername("  Alice "); got != "alice" {
+		t.Errorf("normalizeUsername() = %q, want %q", got, "alice")
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantOk   bool
+	}{
+		{name: "valid", username: "alice_1", wantOk: true},
+		{name: "shortest", username: "abc", wantOk: true},
+		{name: "longest", username: "abcdefghijabcdefghij", wantOk: true},
+		{name: "multibyte counted as characters", username: "пользователь", wantOk: true},
+		{name: "too short", username: "ab"},
+		{name: "too long", username: "abcdefghijabcdefghijk"},
+		{name: "space", username: "ali ce"},
+		{name: "at sign", username: "@alice"},
+		{name: "dash", username: "ali-ce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateUsername(tt.username); (got == "") != tt.wantOk {
+				t.Errorf("validateUsername(%q) = %q, wantOk %v", tt.username, got, tt.wantOk)
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"mime/multipart"
+	"time"
 
+	"github.com/BloggingApp/user-service/internal/config"
 	"github.com/BloggingApp/user-service/internal/dto"
 	"github.com/BloggingApp/user-service/internal/model"
 	"github.com/BloggingApp/user-service/internal/rabbitmq"
 	"github.com/BloggingApp/user-service/internal/repository"
+	"github.com/BloggingApp/user-service/internal/sociallink"
 	"github.com/google/uuid"
 	jwtmanager "github.com/morf1lo/jwt-pair-manager"
 	"go.uber.org/zap"
@@ -27,29 +30,88 @@ type Auth interface {
 
 type User interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser, error)
+	AddPendingFollowCounts(ctx context.Context, user *model.FullUser) error
 	FindByUsername(ctx context.Context, getterID *uuid.UUID, username string) (*dto.GetUserDto, error)
-	SearchByUsername(ctx context.Context, username string, limit int, offset int) ([]*dto.GetUserDto, error)
-	FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error)
-	Follow(ctx context.Context, follower model.Follower) error
+	FindRelationship(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) (*model.Relationship, error)
+	FindRelationships(ctx context.Context, viewerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.Relationship, error)
+	SearchUsers(ctx context.Context, getterID *uuid.UUID, query string, limit int, offset int) ([]*dto.GetUserDto, error)
+	FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error)
+	Follow(ctx context.Context, follower model.Follower) (bool, error)
 	Unfollow(ctx context.Context, follower model.Follower) error
-	UpdateNewPostNotificationsEnabled(ctx context.Context, follower model.Follower) error
-	FindUserFollows(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error)
-	Update(ctx context.Context, user model.FullUser, updates map[string]interface{}) error
-	SetAvatar(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) error
-	AddSocialLink(ctx context.Context, user model.FullUser, link string) error
+	RemoveFollower(ctx context.Context, user model.FullUser, followerID uuid.UUID) error
+	FindFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullFollowRequest, error)
+	ApproveFollowRequest(ctx context.Context, user model.FullUser, followerID uuid.UUID) error
+	RejectFollowRequest(ctx context.Context, user model.FullUser, followerID uuid.UUID) error
+	UpdateNotificationPreferences(ctx context.Context, follower model.Follower, update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, error)
+	FindUserFollows(ctx context.Context, id uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error)
+	ViewUserFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error)
+	FindMutualFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) (*dto.MutualFollowers, error)
+	ImportFollows(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) (*model.FollowImport, error)
+	FindFollowImport(ctx context.Context, userID uuid.UUID, importID uuid.UUID) (*model.FollowImport, error)
+	ExportFollowers(ctx context.Context, userID uuid.UUID, write func(follows []*model.FullFollower) error) error
+	ExportFollows(ctx context.Context, userID uuid.UUID, write func(follows []*model.FullFollower) error) error
+	StreamNotifiedFollowers(ctx context.Context, userID uuid.UUID, highlighted bool, write func(followers []*model.NotifiedFollower) error) error
+	ViewUserFollows(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error)
+	Block(ctx context.Context, user model.FullUser, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, user model.FullUser, blockedID uuid.UUID) error
+	Mute(ctx context.Context, user model.FullUser, mutedID uuid.UUID, expiresAt *time.Time) error
+	Unmute(ctx context.Context, user model.FullUser, mutedID uuid.UUID) error
+	FindMutes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullMute, error)
+	Update(ctx context.Context, user model.FullUser, req dto.UpdateProfileReq, ifUpdatedAt *time.Time) (time.Time, error)
+	SetAvatar(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader, crop dto.AvatarCropReq) error
+	SetGeneratedAvatar(ctx context.Context, user model.FullUser) error
+	RemoveAvatar(ctx context.Context, user model.FullUser) error
+	SetBanner(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) error
+	AddSocialLink(ctx context.Context, user model.FullUser, req dto.AddSocialLinkReq) error
+	UpdateSocialLink(ctx context.Context, user model.FullUser, platform string, req dto.UpdateSocialLinkReq) error
+	ReorderSocialLinks(ctx context.Context, user model.FullUser, platforms []string) error
 	DeleteSocialLink(ctx context.Context, user model.FullUser, platform string) error
 }
 
+type Moderation interface {
+	Report(ctx context.Context, reporter model.FullUser, targetID uuid.UUID, req dto.ReportReq) error
+	FindReports(ctx context.Context, filter model.ReportsFilter, limit int, offset int) ([]*model.FullReport, error)
+	TriageReport(ctx context.Context, moderator model.FullUser, reportID uuid.UUID, req dto.TriageReportReq) error
+	ResolveReport(ctx context.Context, moderator model.FullUser, reportID uuid.UUID, req dto.ResolveReportReq) error
+	LiftSuspension(ctx context.Context, userID uuid.UUID) error
+}
+
+type SocialLinkVerification interface {
+	Enqueue(link model.SocialLinkToVerify)
+	Run(ctx context.Context)
+}
+
+type Suggestions interface {
+	FindSuggestions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.Suggestion, error)
+	DismissSuggestion(ctx context.Context, userID uuid.UUID, dismissedID uuid.UUID) error
+	Run(ctx context.Context)
+}
+
+type FollowCounts interface {
+	StartReconciliation(ctx context.Context) error
+	LastReconciliation(ctx context.Context) (*model.FollowCountsReconciliation, error)
+	Run(ctx context.Context)
+}
+
 type Service struct {
 	Auth
 	User
+	Moderation
+	SocialLinkVerification
+	Suggestions
+	FollowCounts
 }
 
-func New(logger *zap.Logger, repo *repository.Repository, rabbitmq *rabbitmq.MQConn) *Service {
-	userService := newUserService(logger, repo, rabbitmq)
+func New(logger *zap.Logger, repo *repository.Repository, rabbitmq *rabbitmq.MQConn, socialLinks *sociallink.Registry, socialLinkVerifier *sociallink.Verifier, socialLinkVerificationCfg config.SocialLinkVerificationConfig, suggestionsCfg config.SuggestionsConfig, followCountsCfg config.FollowCountsConfig) *Service {
+	socialLinkVerificationService := newSocialLinkVerificationService(logger, repo, socialLinkVerifier, socialLinkVerificationCfg)
+	userService := newUserService(logger, repo, rabbitmq, socialLinks, socialLinkVerificationService)
 
 	return &Service{
 		Auth: newAuthService(logger, repo, rabbitmq, userService),
 		User: userService,
+		Moderation: newModerationService(logger, repo, rabbitmq, userService),
+		SocialLinkVerification: socialLinkVerificationService,
+		Suggestions: newSuggestionsService(logger, repo, userService, suggestionsCfg),
+		FollowCounts: newFollowCountsService(logger, repo, followCountsCfg),
 	}
 }
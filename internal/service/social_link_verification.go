@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/config"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/repository"
+	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
+	"github.com/BloggingApp/user-service/internal/sociallink"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	SOCIAL_LINK_VERIFICATION_QUEUE_SIZE = 100
+	SOCIAL_LINK_VERIFICATION_BATCH_SIZE = 50
+	// A link whose page couldn't be fetched is retried after this long, twice as
+	// long after every failed check in a row, up to MAX_SOCIAL_LINK_VERIFICATION_RETRY_AFTER
+	SOCIAL_LINK_VERIFICATION_RETRY_AFTER = time.Minute * 15
+	MAX_SOCIAL_LINK_VERIFICATION_RETRY_AFTER = time.Hour * 24 * 7
+	// Used when the intervals are missing from the config
+	DEFAULT_SOCIAL_LINK_VERIFICATION_SCAN_INTERVAL = time.Minute * 10
+	DEFAULT_SOCIAL_LINK_VERIFICATION_RECHECK_AFTER = time.Hour * 24
+)
+
+type socialLinkVerificationService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	verifier *sociallink.Verifier
+	cfg config.SocialLinkVerificationConfig
+	queue chan model.SocialLinkToVerify
+}
+
+func newSocialLinkVerificationService(logger *zap.Logger, repo *repository.Repository, verifier *sociallink.Verifier, cfg config.SocialLinkVerificationConfig) SocialLinkVerification {
+	if cfg.ScanInterval <= 0 {
+		cfg.ScanInterval = DEFAULT_SOCIAL_LINK_VERIFICATION_SCAN_INTERVAL
+	}
+	if cfg.RecheckAfter <= 0 {
+		cfg.RecheckAfter = DEFAULT_SOCIAL_LINK_VERIFICATION_RECHECK_AFTER
+	}
+
+	return &socialLinkVerificationService{
+		logger: logger,
+		repo: repo,
+		verifier: verifier,
+		cfg: cfg,
+		queue: make(chan model.SocialLinkToVerify, SOCIAL_LINK_VERIFICATION_QUEUE_SIZE),
+	}
+}
+
+// Enqueue schedules an immediate check of the link. When the queue is full the
+// link is left to the periodic scan, which picks up links that were never checked first.
+func (s *socialLinkVerificationService) Enqueue(link model.SocialLinkToVerify) {
+	select {
+	case s.queue <- link:
+	default:
+		s.logger.Sugar().Warnf("social link verification queue is full, user(%s) link(%s) is left to the periodic scan", link.UserID.String(), link.Platform)
+	}
+}
+
+func (s *socialLinkVerificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case link := <-s.queue:
+			s.verify(ctx, link)
+		case <-ticker.C:
+			s.recheck(ctx)
+		}
+	}
+}
+
+func (s *socialLinkVerificationService) recheck(ctx context.Context) {
+	now := time.Now()
+	for {
+		links, err := s.repo.Postgres.User.FindSocialLinksToVerify(ctx, now, SOCIAL_LINK_VERIFICATION_BATCH_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get social links to verify from postgres: %s", err.Error())
+			return
+		}
+
+		for _, link := range links {
+			if ctx.Err() != nil {
+				return
+			}
+			s.verify(ctx, *link)
+		}
+
+		if len(links) < SOCIAL_LINK_VERIFICATION_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+func (s *socialLinkVerificationService) verify(ctx context.Context, link model.SocialLinkToVerify) {
+	verifyCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	profileURL := viper.GetString("client.origin") + "/@" + link.Username
+
+	verified, err := s.verifier.Verify(verifyCtx, link.URL, profileURL)
+	if err != nil {
+		// A page that couldn't be fetched tells nothing about the backlink, so the link keeps its verification
+		s.logger.Sugar().Infof("failed to verify user(%s) social link(%s): %s", link.UserID.String(), link.URL, err.Error())
+
+		if err := s.repo.Postgres.User.FailSocialLinkCheck(ctx, link.SocialLink, time.Now().Add(socialLinkRetryAfter(link.FailedChecks))); err != nil {
+			s.logger.Sugar().Errorf("failed to update user(%s) social link(%s) next check: %s", link.UserID.String(), link.Platform, err.Error())
+		}
+		return
+	}
+
+	checkedAt := time.Now()
+	link.Verified = verified
+	link.VerifiedAt = nil
+	if verified {
+		link.VerifiedAt = &checkedAt
+	}
+
+	if err := s.repo.Postgres.User.UpdateSocialLinkVerification(ctx, link.SocialLink, checkedAt, checkedAt.Add(s.cfg.RecheckAfter)); err != nil {
+		s.logger.Sugar().Errorf("failed to update user(%s) social link(%s) verification: %s", link.UserID.String(), link.Platform, err.Error())
+		return
+	}
+
+	if err := s.repo.Redis.Default.Del(
+		ctx,
+		redisrepo.UserByUsernameKey(link.Username),
+		redisrepo.UserKey(link.UserID.String()),
+	).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete user(%s) cache: %s", link.UserID.String(), err.Error())
+	}
+}
+
+func socialLinkRetryAfter(failedChecks int) time.Duration {
+	retryAfter := SOCIAL_LINK_VERIFICATION_RETRY_AFTER
+	for i := 0; i < failedChecks && retryAfter < MAX_SOCIAL_LINK_VERIFICATION_RETRY_AFTER; i++ {
+		retryAfter *= 2
+	}
+
+	return min(retryAfter, MAX_SOCIAL_LINK_VERIFICATION_RETRY_AFTER)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/user-service/internal/config"
+	"github.com/BloggingApp/user-service/internal/model"
+	"github.com/BloggingApp/user-service/internal/repository"
+	"github.com/BloggingApp/user-service/internal/repository/postgres"
+	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	SUGGESTIONS_COMPUTE_BATCH_SIZE = 500
+	SUGGESTIONS_RECOMPUTE_QUEUE_SIZE = 100
+	// A user without suggestions gets them recomputed at most this often
+	SUGGESTIONS_RECOMPUTE_INTERVAL = time.Minute * 10
+)
+
+type suggestionsService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	userService User
+	cfg config.SuggestionsConfig
+	queue chan uuid.UUID
+}
+
+func newSuggestionsService(logger *zap.Logger, repo *repository.Repository, userService User, cfg config.SuggestionsConfig) Suggestions {
+	return &suggestionsService{
+		logger: logger,
+		repo: repo,
+		userService: userService,
+		cfg: cfg,
+		queue: make(chan uuid.UUID, SUGGESTIONS_RECOMPUTE_QUEUE_SIZE),
+	}
+}
+
+// FindSuggestions gets the user's precomputed suggestions. Users that have none yet,
+// such as the ones registered since the last nightly run, get an empty list and
+// their suggestions queued to be computed.
+func (s *suggestionsService) FindSuggestions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.Suggestion, error) {
+	if limit <= 0 {
+		limit = MAX_SEARCH_LIMIT
+	}
+	maximumLimit(&limit)
+
+	suggestions, err := s.repo.Postgres.Suggestion.FindSuggestions(ctx, userID, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) suggestions from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	if len(suggestions) == 0 {
+		s.enqueue(ctx, userID)
+	}
+
+	if suggestions == nil {
+		suggestions = []*model.Suggestion{}
+	}
+
+	return suggestions, nil
+}
+
+func (s *suggestionsService) DismissSuggestion(ctx context.Context, userID uuid.UUID, dismissedID uuid.UUID) error {
+	if _, err := s.userService.FindByID(ctx, dismissedID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Postgres.Suggestion.DismissSuggestion(ctx, userID, dismissedID); err != nil {
+		s.logger.Sugar().Errorf("failed to dismiss user(%s) suggestion of user(%s) in postgres: %s", userID.String(), dismissedID.String(), err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// enqueue schedules the computation of the user's suggestions, unless it has been
+// scheduled within SUGGESTIONS_RECOMPUTE_INTERVAL. When the queue is full the user
+// is left to the next request or the nightly run.
+func (s *suggestionsService) enqueue(ctx context.Context, userID uuid.UUID) {
+	queued, err := s.repo.Redis.Default.SetNX(ctx, redisrepo.SuggestionsRecomputeKey(userID.String()), 1, SUGGESTIONS_RECOMPUTE_INTERVAL)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) suggestions recompute in redis: %s", userID.String(), err.Error())
+		return
+	}
+	if !queued {
+		return
+	}
+
+	select {
+	case s.queue <- userID:
+	default:
+		s.logger.Sugar().Warnf("suggestions recompute queue is full, user(%s) is left to the nightly run", userID.String())
+		s.repo.Redis.Default.Del(ctx, redisrepo.SuggestionsRecomputeKey(userID.String()))
+	}
+}
+
+// Run recomputes every user's suggestions once a day at cfg.ComputeHour (UTC) and
+// the queued users' ones meanwhile.
+func (s *suggestionsService) Run(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case userID := <-s.queue:
+				s.compute(ctx, userID)
+			}
+		}
+	}()
+
+	for {
+		timer := time.NewTimer(untilHour(time.Now().UTC(), s.cfg.ComputeHour))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := s.repo.Postgres.Suggestion.WithComputeLock(ctx, s.computeAll); err != nil {
+				if err == postgres.ErrLocked {
+					s.logger.Sugar().Info("suggestions are being computed by another instance")
+					continue
+				}
+
+				s.logger.Sugar().Errorf("failed to lock suggestions computation in postgres: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (s *suggestionsService) computeAll(ctx context.Context) error {
+	startedAt := time.Now()
+	computed := 0
+
+	after := uuid.Nil
+	for {
+		userIDs, err := s.repo.Postgres.Suggestion.FindUserIDs(ctx, after, SUGGESTIONS_COMPUTE_BATCH_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user ids to compute suggestions from postgres: %s", err.Error())
+			return nil
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := s.compute(ctx, userID); err == nil {
+				computed++
+			}
+		}
+
+		if len(userIDs) < SUGGESTIONS_COMPUTE_BATCH_SIZE {
+			break
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+
+	s.logger.Sugar().Infof("computed suggestions for %d users in %s", computed, time.Since(startedAt).String())
+	return nil
+}
+
+func (s *suggestionsService) compute(ctx context.Context, userID uuid.UUID) error {
+	activeSince := time.Now().Add(-s.cfg.ActiveWithin)
+	if err := s.repo.Postgres.Suggestion.ComputeSuggestions(ctx, userID, s.cfg.Count, activeSince); err != nil {
+		s.logger.Sugar().Errorf("failed to compute user(%s) suggestions in postgres: %s", userID.String(), err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// untilHour is how long it is from now till the next hour:00.
+func untilHour(now time.Time, hour int) time.Duration {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.Add(time.Hour * 24)
+	}
+
+	return next.Sub(now)
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUntilHour(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		hour int
+		want time.Duration
+	}{
+		{name: "later today", now: day(1, 30), hour: 3, want: time.Hour + time.Minute * 30},
+		{name: "right now", now: day(3, 0), hour: 3, want: time.Hour * 24},
+		{name: "just passed", now: day(3, 0).Add(time.Nanosecond), hour: 3, want: time.Hour * 24 - time.Nanosecond},
+		{name: "tomorrow", now: day(23, 0), hour: 3, want: time.Hour * 4},
+		{name: "midnight", now: day(12, 0), hour: 0, want: time.Hour * 12},
+		{name: "other zone", now: time.Date(2026, 10, 19, 2, 0, 0, 0, time.FixedZone("MSK", 3 * 60 * 60)), hour: 3, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := untilHour(tt.now, tt.hour); got != tt.want {
+				t.Errorf("untilHour(%v, %d) = %v, want %v", tt.now, tt.hour, got, tt.want)
+			}
+		})
+	}
+}
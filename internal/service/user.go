@@ -5,21 +5,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
 	"io"
 	"mime/multipart"
 	"net/http"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/BloggingApp/user-service/internal/dto"
 	"github.com/BloggingApp/user-service/internal/model"
 	"github.com/BloggingApp/user-service/internal/rabbitmq"
 	"github.com/BloggingApp/user-service/internal/repository"
 	"github.com/BloggingApp/user-service/internal/repository/redisrepo"
-	urlverifier "github.com/davidmytton/url-verifier"
+	"github.com/BloggingApp/user-service/internal/sociallink"
 	"github.com/google/uuid"
-	"github.com/h2non/filetype"
 	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
@@ -31,30 +32,35 @@ type userService struct {
 	repo *repository.Repository
 	rabbitmq *rabbitmq.MQConn
 	httpClient *http.Client
-	socialLinkTypes map[string]string
+	socialLinks *sociallink.Registry
+	socialLinkVerification SocialLinkVerification
 }
 
 const (
 	MAX_SEARCH_LIMIT = 10
-
-	GITHUB_LINK_TYPE = "github"
-	TELEGRAM_LINK_TYPE = "telegram"
-	MAX_SOCIAL_LINKS_COUNT = 2
+	MAX_RELATIONSHIPS_IDS = 100
+	MUTUAL_FOLLOWERS_SAMPLE = 3
+	// Mutual followers of users with at least this many followers are found in redis
+	LARGE_ACCOUNT_FOLLOWERS = 10000
+	// The cached ID sets are loaded from postgres and added to redis this many IDs at a time
+	ID_SET_CHUNK_SIZE = 1000
+	ID_SET_TTL = time.Minute * 10
+	NOTIFIED_FOLLOWERS_BATCH_SIZE = 1000
 )
 
-func newUserService(logger *zap.Logger, repo *repository.Repository, rabbitmq *rabbitmq.MQConn) User {
+func newUserService(logger *zap.Logger, repo *repository.Repository, rabbitmq *rabbitmq.MQConn, socialLinks *sociallink.Registry, socialLinkVerification SocialLinkVerification) User {
 	return &userService{
 		logger: logger,
 		repo: repo,
 		rabbitmq: rabbitmq,
 		httpClient: &http.Client{},
-		socialLinkTypes: map[string]string{
-			"https://github.com/": GITHUB_LINK_TYPE,
-			"https://t.me/": TELEGRAM_LINK_TYPE,
-		},
+		socialLinks: socialLinks,
+		socialLinkVerification: socialLinkVerification,
 	}
 }
 
+// FindByID returns the user with the follow counts stored in postgres, the callers
+// that show the counts add the pending ones with AddPendingFollowCounts.
 func (s *userService) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUser, error) {
 	userCache, err := redisrepo.Get[model.FullUser](s.repo.Redis.Default, ctx, redisrepo.UserKey(id.String()))
 	if err == nil {
@@ -66,254 +72,955 @@ func (s *userService) FindByID(ctx context.Context, id uuid.UUID) (*model.FullUs
 		return nil, ErrInternal
 	}
 
-	user, err := s.repo.Postgres.FindByID(ctx, id)
+	// Read before the counts, see SetJSONIfFlushGeneration
+	flushGeneration, err := s.repo.Redis.Counters.FlushGeneration(ctx)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get follow counts flush generation from redis: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	user, err := s.repo.Postgres.FindByID(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find user(%s) from postgres: %s", id.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	s.decorateSocialLinks(user.SocialLinks)
+
+	if err := s.repo.Redis.Counters.SetJSONIfFlushGeneration(ctx, flushGeneration, redisrepo.UserKey(id.String()), user, time.Hour * 3); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) in redis: %s", id.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return user, nil
+}
+
+func (s *userService) AddPendingFollowCounts(ctx context.Context, user *model.FullUser) error {
+	return s.addPendingFollowCounts(ctx, user.ID, &user.Followers, &user.Follows)
+}
+
+// addPendingFollowCounts adds the count deltas that haven't been flushed to postgres
+// yet, the cached profiles hold the counts from postgres only.
+func (s *userService) addPendingFollowCounts(ctx context.Context, userID uuid.UUID, followers *int64, follows *int64) error {
+	pending, err := s.repo.Redis.Counters.PendingFollowCounts(ctx, []string{userID.String()})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) pending follow counts from redis: %s", userID.String(), err.Error())
+		return ErrInternal
+	}
+
+	*followers += pending[userID.String()].Followers
+	*follows += pending[userID.String()].Follows
+
+	return nil
+}
+
+// incrFollowCounts counts the follows (delta 1) or unfollows (delta -1) in redis,
+// they reach postgres with the next flush. A failure only makes the counts drift
+// until the next reconciliation, so it doesn't fail the request.
+func (s *userService) incrFollowCounts(ctx context.Context, follows []model.Follower, delta int64) {
+	if len(follows) == 0 {
+		return
+	}
+
+	deltas := make(map[string]model.FollowCountDeltas)
+	for _, follow := range follows {
+		userDeltas := deltas[follow.UserID.String()]
+		userDeltas.Followers += delta
+		deltas[follow.UserID.String()] = userDeltas
+
+		followerDeltas := deltas[follow.FollowerID.String()]
+		followerDeltas.Follows += delta
+		deltas[follow.FollowerID.String()] = followerDeltas
+	}
+
+	if err := s.repo.Redis.Counters.IncrFollowCounts(ctx, deltas); err != nil {
+		s.logger.Sugar().Errorf("failed to increment follow counts in redis: %s", err.Error())
+	}
+}
+
+// FindByUsername caches the profile without the getter's relationship to it,
+// which is looked up on every call so that follows and approvals show up at once.
+func (s *userService) FindByUsername(ctx context.Context, getterID *uuid.UUID, username string) (*dto.GetUserDto, error) {
+	userDto, err := redisrepo.Get[dto.GetUserDto](s.repo.Redis.Default, ctx, redisrepo.UserByUsernameKey(username))
+	if err != nil {
+		if err != redis.Nil {
+			s.logger.Sugar().Errorf("failed to get user from redis: %s", err.Error())
+			return nil, ErrInternal
+		}
+
+		// Read before the counts, see SetJSONIfFlushGeneration
+		flushGeneration, err := s.repo.Redis.Counters.FlushGeneration(ctx)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get follow counts flush generation from redis: %s", err.Error())
+			return nil, ErrInternal
+		}
+
+		user, err := s.repo.Postgres.User.FindByUsername(ctx, username)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return nil, ErrUserNotFound
+			}
+
+			s.logger.Sugar().Errorf("failed to get user from postgres: %s", err.Error())
+			return nil, ErrInternal
+		}
+
+		s.decorateSocialLinks(user.SocialLinks)
+
+		userDto = dto.GetUserDtoFromFullUser(*user)
+
+		if err := s.repo.Redis.Counters.SetJSONIfFlushGeneration(ctx, flushGeneration, redisrepo.UserByUsernameKey(username), userDto, time.Hour * 3); err != nil {
+			s.logger.Sugar().Errorf("failed to set user in redis: %s", err.Error())
+			return nil, ErrInternal
+		}
+	}
+
+	if err := s.addPendingFollowCounts(ctx, userDto.ID, &userDto.Followers, &userDto.Follows); err != nil {
+		return nil, err
+	}
+
+	if getterID != nil && *getterID != userDto.ID {
+		relationship, err := s.repo.Postgres.User.FindRelationship(ctx, *getterID, userDto.ID)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user(%s) relationship with user(%s) from postgres: %s", getterID.String(), userDto.ID.String(), err.Error())
+			return nil, ErrInternal
+		}
+
+		// Users that blocked the getter look as if they didn't exist
+		if relationship.IsBlockedBy {
+			return nil, ErrUserNotFound
+		}
+
+		userDto.SetRelationship(*relationship)
+	}
+
+	return userDto, nil
+}
+
+// FindRelationship tells how the viewer relates to the user, users that blocked the viewer are not found.
+func (s *userService) FindRelationship(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) (*model.Relationship, error) {
+	if viewerID == userID {
+		return nil, ErrRelationshipWithYourself
+	}
+
+	if _, err := s.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	relationship, err := s.repo.Postgres.User.FindRelationship(ctx, viewerID, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) relationship with user(%s) from postgres: %s", viewerID.String(), userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	if relationship.IsBlockedBy {
+		return nil, ErrUserNotFound
+	}
+
+	return relationship, nil
+}
+
+// FindRelationships is FindRelationship for up to MAX_RELATIONSHIPS_IDS users, the viewer,
+// users that don't exist and users that blocked the viewer are left out.
+func (s *userService) FindRelationships(ctx context.Context, viewerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]model.Relationship, error) {
+	if len(userIDs) > MAX_RELATIONSHIPS_IDS {
+		return nil, ErrTooManyIDs
+	}
+
+	relationships, err := s.repo.Postgres.User.FindRelationships(ctx, viewerID, userIDs)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) relationships from postgres: %s", viewerID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	delete(relationships, viewerID)
+	for userID, relationship := range relationships {
+		if relationship.IsBlockedBy {
+			delete(relationships, userID)
+		}
+	}
+
+	return relationships, nil
+}
+
+// SearchUsers finds users by username or display name, most relevant first. The results
+// of a query are the same for everyone, so they are cached by the normalized query.
+func (s *userService) SearchUsers(ctx context.Context, getterID *uuid.UUID, query string, limit int, offset int) ([]*dto.GetUserDto, error) {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	if limit <= 0 {
+		limit = MAX_SEARCH_LIMIT
+	}
+	maximumLimit(&limit)
+
+	searchResultsCache, err := redisrepo.GetMany[dto.GetUserDto](s.repo.Redis.Default, ctx, redisrepo.SearchResultsKey(query, limit, offset))
+	if err == nil {
+		return s.withoutBlockers(ctx, getterID, searchResultsCache)
+	}
+
+	if err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get value from redis: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	searchResults, err := s.repo.Postgres.User.SearchUsers(ctx, query, limit, offset)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to search users by query(%s) in postgres: %s", query, err.Error())
+		return nil, ErrInternal
+	}
+
+	searchResultsDto := s.convertFullUsersToGetUserDtos(searchResults)
+
+	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.SearchResultsKey(query, limit, offset), searchResultsDto, time.Minute * 5); err != nil {
+		s.logger.Sugar().Errorf("failed to set value in redis: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return s.withoutBlockers(ctx, getterID, searchResultsDto)
+}
+
+// withoutBlockers drops the users that blocked the getter, search results are
+// cached for everyone so they are filtered after the cache.
+func (s *userService) withoutBlockers(ctx context.Context, getterID *uuid.UUID, users []*dto.GetUserDto) ([]*dto.GetUserDto, error) {
+	if getterID == nil || len(users) == 0 {
+		return users, nil
+	}
+
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	blockerIDs, err := s.repo.Postgres.User.FindBlockerIDs(ctx, *getterID, userIDs)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get users that blocked user(%s) from postgres: %s", getterID.String(), err.Error())
+		return nil, ErrInternal
+	}
+	if len(blockerIDs) == 0 {
+		return users, nil
+	}
+
+	blockers := make(map[uuid.UUID]bool, len(blockerIDs))
+	for _, blockerID := range blockerIDs {
+		blockers[blockerID] = true
+	}
+
+	visible := make([]*dto.GetUserDto, 0, len(users))
+	for _, user := range users {
+		if !blockers[user.ID] {
+			visible = append(visible, user)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *userService) convertFullUsersToGetUserDtos(users []*model.FullUser) []*dto.GetUserDto {
+	dtos := make([]*dto.GetUserDto, len(users))
+	for i, user := range users {
+		s.decorateSocialLinks(user.SocialLinks)
+		dtos[i] = dto.GetUserDtoFromFullUser(*user)
+	}
+	return dtos	
+}
+
+// FindUserFollowers lists the user's followers newest first. Only the first page
+// is cached, with the page size of MAX_SEARCH_LIMIT, smaller pages are cut from it.
+func (s *userService) FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error) {
+	return s.findFollowsPage(ctx, id, limit, cursor, redisrepo.UserFollowersKey(id.String()), s.repo.Postgres.User.FindUserFollowers)
+}
+
+func (s *userService) Follow(ctx context.Context, follower model.Follower) (bool, error) {
+	if follower.FollowerID.String() == follower.UserID.String() {
+		return false, ErrFollowToYourself
+	}
+
+	user, err := s.FindByID(ctx, follower.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	relationship, err := s.repo.Postgres.User.FindRelationship(ctx, follower.FollowerID, follower.UserID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) relationship with user(%s) from postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+		return false, ErrInternal
+	}
+	if relationship.IsBlocking || relationship.IsBlockedBy {
+		return false, ErrFollowBlocked
+	}
+	if relationship.IsFollowing {
+		return false, ErrAlreadyFollowing
+	}
+
+	if user.IsPrivate {
+		if relationship.FollowRequested {
+			return true, nil
+		}
+
+		if err := s.repo.Postgres.User.CreateFollowRequest(ctx, follower); err != nil {
+			s.logger.Sugar().Errorf("failed to create follow request from user(%s) to user(%s) in postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+			return false, ErrInternal
+		}
+
+		return true, nil
+	}
+
+	followed, err := s.repo.Postgres.User.Follow(ctx, follower)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to subscribe user(%s) on user(%s) in postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+		return false, ErrInternal
+	}
+	if !followed {
+		return false, nil
+	}
+
+	s.incrFollowCounts(ctx, []model.Follower{follower}, 1)
+
+	if err := s.publishFollowEvent(dto.FollowEvent{Type: dto.FOLLOW_EVENT_FOLLOWED, UserID: follower.UserID, FollowerID: follower.FollowerID}); err != nil {
+		return false, err
+	}
+
+	if err := s.deleteFollowCache(ctx, *user, follower.FollowerID); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Unfollow also cancels a pending follow request.
+func (s *userService) Unfollow(ctx context.Context, follower model.Follower) error {
+	if _, err := s.repo.Postgres.User.DeleteFollowRequest(ctx, follower.UserID, follower.FollowerID); err != nil {
+		s.logger.Sugar().Errorf("failed to cancel follow request from user(%s) to user(%s): %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+		return ErrInternal
+	}
+
+	unfollowed, err := s.repo.Postgres.User.Unfollow(ctx, follower)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to unfollow follower(%s) from user(%s): %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+		return ErrInternal
+	}
+	if !unfollowed {
+		return nil
+	}
+
+	s.incrFollowCounts(ctx, []model.Follower{follower}, -1)
+
+	if err := s.publishFollowEvent(dto.FollowEvent{Type: dto.FOLLOW_EVENT_UNFOLLOWED, UserID: follower.UserID, FollowerID: follower.FollowerID}); err != nil {
+		return err
+	}
+
+	user, err := s.FindByID(ctx, follower.UserID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.deleteFollowCache(ctx, *user, follower.FollowerID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveFollower ends the follower's follow without blocking, so the follower
+// may follow again.
+func (s *userService) RemoveFollower(ctx context.Context, user model.FullUser, followerID uuid.UUID) error {
+	removed, err := s.repo.Postgres.User.Unfollow(ctx, model.Follower{UserID: user.ID, FollowerID: followerID})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to remove follower(%s) of user(%s): %s", followerID.String(), user.ID.String(), err.Error())
+		return ErrInternal
+	}
+	if !removed {
+		return nil
+	}
+
+	s.incrFollowCounts(ctx, []model.Follower{{UserID: user.ID, FollowerID: followerID}}, -1)
+
+	if err := s.publishFollowEvent(dto.FollowEvent{
+		Type: dto.FOLLOW_EVENT_UNFOLLOWED,
+		UserID: user.ID,
+		FollowerID: followerID,
+		Reason: dto.FOLLOW_UNFOLLOWED_REASON_REMOVED,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.deleteFollowCache(ctx, user, followerID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) FindFollowRequests(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullFollowRequest, error) {
+	maximumLimit(&limit)
+
+	requests, err := s.repo.Postgres.User.FindFollowRequests(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) follow requests from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return requests, nil
+}
+
+func (s *userService) ApproveFollowRequest(ctx context.Context, user model.FullUser, followerID uuid.UUID) error {
+	approved, err := s.repo.Postgres.User.ApproveFollowRequest(ctx, user.ID, followerID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to approve user(%s) follow request from user(%s): %s", user.ID.String(), followerID.String(), err.Error())
+		return ErrInternal
+	}
+	if !approved {
+		return ErrFollowRequestNotFound
+	}
+
+	s.incrFollowCounts(ctx, []model.Follower{{UserID: user.ID, FollowerID: followerID}}, 1)
+
+	if err := s.publishFollowEvent(dto.FollowEvent{Type: dto.FOLLOW_EVENT_FOLLOWED, UserID: user.ID, FollowerID: followerID}); err != nil {
+		return err
+	}
+
+	if err := s.deleteFollowCache(ctx, user, followerID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) RejectFollowRequest(ctx context.Context, user model.FullUser, followerID uuid.UUID) error {
+	deleted, err := s.repo.Postgres.User.DeleteFollowRequest(ctx, user.ID, followerID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to reject user(%s) follow request from user(%s): %s", user.ID.String(), followerID.String(), err.Error())
+		return ErrInternal
+	}
+	if !deleted {
+		return ErrFollowRequestNotFound
+	}
+
+	return nil
+}
+
+// approveAllFollowRequests is run when a private account becomes public.
+func (s *userService) approveAllFollowRequests(ctx context.Context, user model.FullUser) error {
+	followerIDs, err := s.repo.Postgres.User.ApproveAllFollowRequests(ctx, user.ID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to approve all user(%s) follow requests: %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	follows := make([]model.Follower, len(followerIDs))
+	for i, followerID := range followerIDs {
+		follows[i] = model.Follower{UserID: user.ID, FollowerID: followerID}
+	}
+	s.incrFollowCounts(ctx, follows, 1)
+
+	for _, followerID := range followerIDs {
+		if err := s.publishFollowEvent(dto.FollowEvent{Type: dto.FOLLOW_EVENT_FOLLOWED, UserID: user.ID, FollowerID: followerID}); err != nil {
+			return err
+		}
+
+		if err := s.deleteFollowCache(ctx, user, followerID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Block removes the follows between the users in both directions, so the
+// blocked user has to be let in again after an unblock.
+func (s *userService) Block(ctx context.Context, user model.FullUser, blockedID uuid.UUID) error {
+	if user.ID == blockedID {
+		return ErrBlockYourself
+	}
+
+	blocked, err := s.FindByID(ctx, blockedID)
+	if err != nil {
+		return err
+	}
+
+	removedFollows, blockedNow, err := s.repo.Postgres.User.Block(ctx, user.ID, blockedID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to block user(%s) by user(%s) in postgres: %s", blockedID.String(), user.ID.String(), err.Error())
+		return ErrInternal
+	}
+	if !blockedNow {
+		return ErrAlreadyBlocked
+	}
+
+	s.incrFollowCounts(ctx, removedFollows, -1)
+
+	for _, follower := range removedFollows {
+		if err := s.publishFollowEvent(dto.FollowEvent{
+			Type: dto.FOLLOW_EVENT_UNFOLLOWED,
+			UserID: follower.UserID,
+			FollowerID: follower.FollowerID,
+			Reason: dto.FOLLOW_UNFOLLOWED_REASON_BLOCK,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.publishBlock(rabbitmq.USERS_BLOCKED_EXCHANGE, user.ID, blockedID); err != nil {
+		return err
+	}
+
+	if err := s.deleteFollowCache(ctx, user, blockedID); err != nil {
+		return err
+	}
+	if err := s.deleteFollowCache(ctx, *blocked, user.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) Unblock(ctx context.Context, user model.FullUser, blockedID uuid.UUID) error {
+	unblocked, err := s.repo.Postgres.User.Unblock(ctx, user.ID, blockedID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to unblock user(%s) by user(%s) in postgres: %s", blockedID.String(), user.ID.String(), err.Error())
+		return ErrInternal
+	}
+	if !unblocked {
+		return nil
+	}
+
+	if err := s.publishBlock(rabbitmq.USERS_UNBLOCKED_EXCHANGE, user.ID, blockedID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) publishBlock(exchange string, userID uuid.UUID, blockedID uuid.UUID) error {
+	bodyJSON, err := json.Marshal(dto.BlockEvent{
+		Version: dto.BLOCK_EVENT_VERSION,
+		UserID: userID,
+		BlockedID: blockedID,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal block event to json: %s", err.Error())
+		return ErrInternal
+	}
+	if err := s.rabbitmq.PublishExchange(exchange, bodyJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish rabbitmq event to exchange(%s): %s", exchange, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// Mute doesn't touch the follow, so the muted user isn't notified in any way.
+// The event carries expires_at and consumers stop applying the mute after it.
+func (s *userService) Mute(ctx context.Context, user model.FullUser, mutedID uuid.UUID, expiresAt *time.Time) error {
+	if user.ID == mutedID {
+		return ErrMuteYourself
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrMuteExpiresInPast
+	}
+
+	if _, err := s.FindByID(ctx, mutedID); err != nil {
+		return err
+	}
+
+	mute := model.Mute{
+		UserID: user.ID,
+		MutedID: mutedID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Postgres.User.Mute(ctx, mute); err != nil {
+		s.logger.Sugar().Errorf("failed to mute user(%s) by user(%s) in postgres: %s", mutedID.String(), user.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	if err := s.publishMute(rabbitmq.USERS_MUTED_EXCHANGE, mute); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) Unmute(ctx context.Context, user model.FullUser, mutedID uuid.UUID) error {
+	unmuted, err := s.repo.Postgres.User.Unmute(ctx, user.ID, mutedID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to unmute user(%s) by user(%s) in postgres: %s", mutedID.String(), user.ID.String(), err.Error())
+		return ErrInternal
+	}
+	if !unmuted {
+		return nil
+	}
+
+	if err := s.publishMute(rabbitmq.USERS_UNMUTED_EXCHANGE, model.Mute{UserID: user.ID, MutedID: mutedID}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *userService) FindMutes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullMute, error) {
+	maximumLimit(&limit)
+
+	mutes, err := s.repo.Postgres.User.FindMutes(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) mutes from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return mutes, nil
+}
+
+func (s *userService) publishMute(exchange string, mute model.Mute) error {
+	bodyJSON, err := json.Marshal(mute)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal model.Mute body to json: %s", err.Error())
+		return ErrInternal
+	}
+	if err := s.rabbitmq.PublishExchange(exchange, bodyJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish rabbitmq event to exchange(%s): %s", exchange, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *userService) publishFollowEvent(event dto.FollowEvent) error {
+	event.Version = dto.FOLLOW_EVENT_VERSION
+	event.OccurredAt = time.Now()
+
+	bodyJSON, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal follow event to json: %s", err.Error())
+		return ErrInternal
+	}
+	if err := s.rabbitmq.PublishTopic(rabbitmq.FOLLOWS_EXCHANGE, dto.FollowEventRoutingKey(event.Type), bodyJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish follow event(%s) to exchange(%s): %s", event.Type, rabbitmq.FOLLOWS_EXCHANGE, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// deleteFollowCache clears the lists affected by a follow of user, the counts
+// are merged with the pending deltas on every read instead.
+func (s *userService) deleteFollowCache(ctx context.Context, user model.FullUser, followerID uuid.UUID) error {
+	if err := s.repo.Redis.Default.Del(
+		ctx,
+		redisrepo.UserFollowersKey(user.ID.String()),
+		redisrepo.UserFollowsKey(followerID.String()),
+		redisrepo.UserFollowerIDsKey(user.ID.String()),
+		redisrepo.UserFollowIDsKey(followerID.String()),
+	).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete redis cache: %s", err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// StreamNotifiedFollowers passes the followers to notify about the user's new post
+// to write, NOTIFIED_FOLLOWERS_BATCH_SIZE at a time.
+func (s *userService) StreamNotifiedFollowers(ctx context.Context, userID uuid.UUID, highlighted bool, write func(followers []*model.NotifiedFollower) error) error {
+	if err := s.repo.Postgres.User.StreamNotifiedFollowers(ctx, userID, highlighted, NOTIFIED_FOLLOWERS_BATCH_SIZE, write); err != nil {
+		s.logger.Sugar().Errorf("failed to stream user(%s) notified followers from postgres: %s", userID.String(), err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// UpdateNotificationPreferences changes how the follower is notified about the user's
+// posts and publishes the preferences before and after the change.
+func (s *userService) UpdateNotificationPreferences(ctx context.Context, follower model.Follower, update model.NotificationPreferencesUpdate) (*model.NotificationPreferences, error) {
+	previous, preferences, err := s.repo.Postgres.User.UpdateNotificationPreferences(ctx, follower, update)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, ErrUserNotFound
+			return nil, ErrNotFollowing
 		}
 
-		s.logger.Sugar().Errorf("failed to find user(%s) from postgres: %s", id.String(), err.Error())
+		s.logger.Sugar().Errorf("failed to update user(%s)'s notification preferences for author(%s) in postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	if err := s.repo.Redis.SetJSON(ctx, redisrepo.UserKey(id.String()), user, time.Hour * 3); err != nil {
-		s.logger.Sugar().Errorf("failed to set user(%s) in redis: %s", id.String(), err.Error())
-		return nil, ErrInternal
+	if err := s.publishFollowEvent(dto.FollowEvent{
+		Type: dto.FOLLOW_EVENT_NOTIFICATIONS_UPDATED,
+		UserID: follower.UserID,
+		FollowerID: follower.FollowerID,
+		Notifications: preferences,
+		PreviousNotifications: previous,
+	}); err != nil {
+		return nil, err
 	}
 
-	return user, nil
+	return preferences, nil
 }
 
-func (s *userService) FindByUsername(ctx context.Context, getterID *uuid.UUID, username string) (*dto.GetUserDto, error) {
-	userCache, err := redisrepo.Get[dto.GetUserDto](s.repo.Redis.Default, ctx, redisrepo.UserByUsernameKey(username))
-	if err == nil {
-		return userCache, nil
-	}
+// FindUserFollows lists the users the user follows newest first, it is cached like FindUserFollowers.
+func (s *userService) FindUserFollows(ctx context.Context, id uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error) {
+	return s.findFollowsPage(ctx, id, limit, cursor, redisrepo.UserFollowsKey(id.String()), s.repo.Postgres.User.FindUserFollows)
+}
 
-	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get user from redis: %s", err.Error())
-		return nil, ErrInternal
+// ViewUserFollowers lists another user's followers as FindUserFollowers does, as long
+// as the viewer may see them, and tells for every follower how they relate to the viewer.
+func (s *userService) ViewUserFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error) {
+	if _, err := s.checkFollowsVisible(ctx, viewerID, userID); err != nil {
+		return nil, err
 	}
 
-	user, err := s.repo.Postgres.User.FindByUsername(ctx, getterID, username)
+	page, err := s.FindUserFollowers(ctx, userID, limit, cursor)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, pgx.ErrNoRows
-		}
+		return nil, err
+	}
 
-		s.logger.Sugar().Errorf("failed to get user from postgres: %s", err.Error())
-		return nil, ErrInternal
+	return page, s.annotateFollowStates(ctx, viewerID, page.Items)
+}
+
+// ViewUserFollows is ViewUserFollowers for the users another user follows.
+func (s *userService) ViewUserFollows(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID, limit int, cursor string) (*dto.FollowsPage, error) {
+	if _, err := s.checkFollowsVisible(ctx, viewerID, userID); err != nil {
+		return nil, err
 	}
-	
-	userDto := dto.GetUserDtoFromFullUser(*user)
 
-	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.UserByUsernameKey(username), userDto, time.Hour * 3); err != nil {
-		s.logger.Sugar().Errorf("failed to set user in redis: %s", err.Error())
-		return nil, ErrInternal
+	page, err := s.FindUserFollows(ctx, userID, limit, cursor)
+	if err != nil {
+		return nil, err
 	}
 
-	return userDto, nil
+	return page, s.annotateFollowStates(ctx, viewerID, page.Items)
 }
 
-func (s *userService) SearchByUsername(ctx context.Context, username string, limit int, offset int) ([]*dto.GetUserDto, error) {
-	maximumLimit(&limit)
-
-	searchResultsCache, err := redisrepo.GetMany[dto.GetUserDto](s.repo.Redis.Default, ctx, redisrepo.SearchResultsKey(username, limit, offset))
-	if err == nil {
-		return searchResultsCache, nil
+// checkFollowsVisible hides the lists of users that blocked or are blocked by
+// the viewer and of private users the viewer doesn't follow.
+func (s *userService) checkFollowsVisible(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) (*model.FullUser, error) {
+	user, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get value from redis: %s", err.Error())
-		return nil, ErrInternal
+	if viewerID == userID {
+		return user, nil
 	}
 
-	searchResults, err := s.repo.Postgres.User.SearchByUsername(ctx, username, limit, offset)
+	relationship, err := s.repo.Postgres.User.FindRelationship(ctx, viewerID, userID)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to search users by username(%s): %s", username, err.Error())
+		s.logger.Sugar().Errorf("failed to get user(%s) relationship with user(%s) from postgres: %s", viewerID.String(), userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	searchResultsDto := s.convertFullUsersToGetUserDtos(searchResults)
+	if relationship.IsBlockedBy || relationship.IsBlocking {
+		return nil, ErrUserNotFound
+	}
 
-	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.SearchResultsKey(username, limit, offset), searchResultsDto, time.Minute * 5); err != nil {
-		s.logger.Sugar().Errorf("failed to set value in redis: %s", err.Error())
-		return nil, ErrInternal
+	if user.IsPrivate && !relationship.IsFollowing {
+		return nil, ErrPrivateAccount
 	}
 
-	return searchResultsDto, nil
+	return user, nil
 }
 
-func (s *userService) convertFullUsersToGetUserDtos(users []*model.FullUser) []*dto.GetUserDto {
-	dtos := make([]*dto.GetUserDto, len(users))
-	for i, user := range users {
-		dtos[i] = dto.GetUserDtoFromFullUser(*user)
+// FindMutualFollowers counts the followers of user that the viewer follows and
+// samples the most followed of them. Followers of large accounts are intersected
+// in redis instead of postgres.
+func (s *userService) FindMutualFollowers(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) (*dto.MutualFollowers, error) {
+	user, err := s.checkFollowsVisible(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
 	}
-	return dtos	
-}
 
-func (s *userService) FindUserFollowers(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error) {
-	maximumLimit(&limit)
+	if user.Followers < LARGE_ACCOUNT_FOLLOWERS {
+		sample, count, err := s.repo.Postgres.User.FindMutualFollowers(ctx, viewerID, userID, MUTUAL_FOLLOWERS_SAMPLE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user(%s) mutual followers with user(%s) from postgres: %s", userID.String(), viewerID.String(), err.Error())
+			return nil, ErrInternal
+		}
 
-	followersCache, err := redisrepo.GetMany[model.FullFollower](s.repo.Redis.Default, ctx, redisrepo.UserFollowersKey(id.String(), limit, offset))
-	if err == nil {
-		return followersCache, nil
-	}
-	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get followers from redis: %s", err.Error())
-		return nil, ErrInternal
+		return newMutualFollowers(count, sample), nil
 	}
 
-	followers, err := s.repo.Postgres.User.FindUserFollowers(ctx, id, limit, offset)
+	mutualIDs, err := s.findMutualFollowerIDs(ctx, viewerID, userID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, pgx.ErrNoRows
-		}
+		return nil, err
+	}
 
-		s.logger.Sugar().Errorf("failed to get user(%s) followers from postgres: %s", id.String(), err.Error())
-		return nil, ErrInternal
+	if len(mutualIDs) == 0 {
+		return newMutualFollowers(0, nil), nil
 	}
 
-	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.UserFollowersKey(id.String(), limit, offset), followers, time.Minute * 1); err != nil {
-		s.logger.Sugar().Errorf("failed to set user(%s) followers in redis: %s", id.String(), err.Error())
+	sample, err := s.repo.Postgres.User.FindFollowersByIDs(ctx, userID, mutualIDs, MUTUAL_FOLLOWERS_SAMPLE)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) followers by ids from postgres: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	return followers, nil
+	return newMutualFollowers(int64(len(mutualIDs)), sample), nil
 }
 
-func (s *userService) Follow(ctx context.Context, follower model.Follower) error {
-	if follower.FollowerID.String() == follower.UserID.String() {
-		return ErrFollowToYourself
+func newMutualFollowers(count int64, sample []*model.FullFollower) *dto.MutualFollowers {
+	if sample == nil {
+		sample = []*model.FullFollower{}
 	}
 
-	isFollowing, err := s.repo.Redis.Default.Get(ctx, redisrepo.IsFollowingKey(follower.FollowerID.String(), follower.UserID.String())).Bool()
-	if err != nil && err != redis.Nil {
-		return ErrInternal
-	}
-	if isFollowing {
-		return ErrAlreadyFollowing
+	return &dto.MutualFollowers{
+		Count: count,
+		Sample: sample,
 	}
+}
 
-	defer func()  {
-		if err := s.repo.Redis.Default.Set(ctx, redisrepo.IsFollowingKey(follower.FollowerID.String(), follower.UserID.String()), true, time.Minute * 5); err != nil {
-			s.logger.Sugar().Errorf("failed to set isFollowed in redis: %s", err.Error())
-		}
-	}()
-
-	userExists, err := s.repo.Postgres.User.ExistsWithID(ctx, follower.UserID)
-	if err != nil {
-		s.logger.Sugar().Errorf("failed to get exists(%s) from postgres: %s", follower.UserID, err.Error())
-		return ErrInternal
-	}
-	if !userExists {
-		return ErrUserNotFound
+func (s *userService) findMutualFollowerIDs(ctx context.Context, viewerID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	followerIDsKey := redisrepo.UserFollowerIDsKey(userID.String())
+	if err := s.cacheIDSet(ctx, followerIDsKey, userID, s.repo.Postgres.User.FindFollowerIDs); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Postgres.User.Follow(ctx, follower); err != nil {
-		s.logger.Sugar().Errorf("failed to subscribe user(%s) on user(%s) in postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
-		return ErrInternal
+	followIDsKey := redisrepo.UserFollowIDsKey(viewerID.String())
+	if err := s.cacheIDSet(ctx, followIDsKey, viewerID, s.repo.Postgres.User.FindFollowIDs); err != nil {
+		return nil, err
 	}
 
-	// Sending follow to rabbitmq
-	followMQBody, err := json.Marshal(map[string]interface{}{
-		"user_id": follower.UserID,
-		"follower_id": follower.FollowerID,
-	})
+	members, err := s.repo.Redis.Sets.SInter(ctx, followerIDsKey, followIDsKey)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to marshal followMQ body: %s", err.Error())
-		return ErrInternal
-	}
-	if err := s.rabbitmq.PublishToQueue(rabbitmq.FOLLOWS_QUEUE, followMQBody); err != nil {
-		s.logger.Sugar().Errorf("failed to publish follow to queue: %s", err.Error())
-		return ErrInternal
+		s.logger.Sugar().Errorf("failed to intersect user(%s) followers with user(%s) follows in redis: %s", userID.String(), viewerID.String(), err.Error())
+		return nil, ErrInternal
 	}
 
-	// Delete cache
-	if err := s.repo.Redis.Default.Del(
-		ctx,
-		redisrepo.UserFollowersKey(follower.UserID.String(), MAX_SEARCH_LIMIT, 0),
-		redisrepo.UserFollowersKey(follower.UserID.String(), MAX_SEARCH_LIMIT, 1),
-		redisrepo.UserFollowsKey(follower.FollowerID.String(), MAX_SEARCH_LIMIT, 0),
-		redisrepo.UserFollowsKey(follower.FollowerID.String(), MAX_SEARCH_LIMIT, 1),
-	).Err(); err != nil {
-		s.logger.Sugar().Errorf("failed to delete redis cache: %s", err.Error())
-		return ErrInternal
+	mutualIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		mutualID, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		mutualIDs = append(mutualIDs, mutualID)
 	}
 
-	return nil
+	return mutualIDs, nil
 }
 
-func (s *userService) Unfollow(ctx context.Context, follower model.Follower) error {
-	isFollowing, err := s.repo.Redis.Get(ctx, redisrepo.IsFollowingKey(follower.FollowerID.String(), follower.UserID.String())).Bool()
-	if err != nil && err != redis.Nil {
+// cacheIDSet loads the IDs into a redis set at key unless it is already there. The set
+// is built a chunk at a time under a key of its own and replaces the one at key when complete.
+func (s *userService) cacheIDSet(ctx context.Context, key string, id uuid.UUID, find func(ctx context.Context, id uuid.UUID, after uuid.UUID, limit int) ([]uuid.UUID, error)) error {
+	exists, err := s.repo.Redis.Sets.Exists(ctx, key)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to check %s in redis: %s", key, err.Error())
 		return ErrInternal
 	}
-	if !isFollowing {
+	if exists > 0 {
 		return nil
 	}
 
-	defer func()  {
-		if err := s.repo.Redis.Default.Set(ctx, redisrepo.IsFollowingKey(follower.FollowerID.String(), follower.UserID.String()), true, time.Minute * 5); err != nil {
-			s.logger.Sugar().Errorf("failed to set isFollowed in redis: %s", err.Error())
+	buildKey := key + ":building:" + uuid.NewString()
+	after := uuid.Nil
+	for {
+		ids, err := find(ctx, id, after, ID_SET_CHUNK_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get %s from postgres: %s", key, err.Error())
+			return ErrInternal
+		}
+
+		members := make([]string, len(ids))
+		for i, id := range ids {
+			members[i] = id.String()
 		}
-	}()
 
-	if err := s.repo.Postgres.User.Unfollow(ctx, follower); err != nil {
-		s.logger.Sugar().Errorf("failed to unfollow follower(%s) from user(%s): %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
+		if err := s.repo.Redis.Sets.AddToSet(ctx, buildKey, members, ID_SET_TTL); err != nil {
+			s.logger.Sugar().Errorf("failed to add to %s in redis: %s", key, err.Error())
+			return ErrInternal
+		}
+
+		if len(ids) < ID_SET_CHUNK_SIZE {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+
+	if err := s.repo.Redis.Sets.PublishSet(ctx, buildKey, key, ID_SET_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set %s in redis: %s", key, err.Error())
 		return ErrInternal
 	}
 
 	return nil
 }
 
-func (s *userService) UpdateNewPostNotificationsEnabled(ctx context.Context, follower model.Follower) error {
-	if err := s.repo.Postgres.User.UpdateNewPostNotificationsEnabled(ctx, follower); err != nil {
-		s.logger.Sugar().Errorf("failed to update user(%s)'s new_post_notifications_enabled for author(%s) in postgres: %s", follower.FollowerID.String(), follower.UserID.String(), err.Error())
-		return ErrInternal
+func (s *userService) annotateFollowStates(ctx context.Context, viewerID uuid.UUID, follows []*model.FullFollower) error {
+	if len(follows) == 0 {
+		return nil
 	}
 
-	bodyJSON, err := json.Marshal(follower)
+	userIDs := make([]uuid.UUID, len(follows))
+	for i, follow := range follows {
+		userIDs[i] = follow.ID
+	}
+
+	states, err := s.repo.Postgres.User.FindFollowStates(ctx, viewerID, userIDs)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to marshal model.Follower body to json: %s", err.Error())
+		s.logger.Sugar().Errorf("failed to get user(%s) follow states from postgres: %s", viewerID.String(), err.Error())
 		return ErrInternal
 	}
-	if err := s.rabbitmq.PublishToQueue(rabbitmq.FOLLOWERS_NEW_POST_NOTIFICATIONS_ENABLED_UPDATES_QUEUE, bodyJSON); err != nil {
-		s.logger.Sugar().Errorf("failed to publish new post notifications enabled update queue: %s", err.Error())
-		return ErrInternal
+
+	for _, follow := range follows {
+		state := states[follow.ID]
+		follow.ViewerFollows = &state.ViewerFollows
+		follow.FollowsViewer = &state.FollowsViewer
 	}
 
 	return nil
 }
 
-func (s *userService) FindUserFollows(ctx context.Context, id uuid.UUID, limit int, offset int) ([]*model.FullFollower, error) {
+type findFollowsFunc func(ctx context.Context, id uuid.UUID, after *model.FollowCursor, limit int) ([]*model.FullFollower, error)
+
+func (s *userService) findFollowsPage(ctx context.Context, id uuid.UUID, limit int, cursor string, firstPageKey string, find findFollowsFunc) (*dto.FollowsPage, error) {
+	if limit <= 0 {
+		limit = MAX_SEARCH_LIMIT
+	}
 	maximumLimit(&limit)
 
-	followsCache, err := redisrepo.GetMany[model.FullFollower](s.repo.Redis.Default, ctx, redisrepo.UserFollowsKey(id.String(), limit, offset))
+	// One more follow than requested tells whether there is a next page
+	if cursor != "" {
+		after, err := model.DecodeFollowCursor(cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		follows, err := find(ctx, id, after, limit + 1)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user(%s) follows page from postgres: %s", id.String(), err.Error())
+			return nil, ErrInternal
+		}
+
+		return dto.NewFollowsPage(follows, limit), nil
+	}
+
+	followsCache, err := redisrepo.GetMany[model.FullFollower](s.repo.Redis.Default, ctx, firstPageKey)
 	if err == nil {
-		return followsCache, nil
+		return dto.NewFollowsPage(followsCache, limit), nil
 	}
 	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get follows from redis: %s", err.Error())
+		s.logger.Sugar().Errorf("failed to get user(%s) follows page from redis: %s", id.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	follows, err := s.repo.Postgres.User.FindUserFollows(ctx, id, limit, offset)
+	follows, err := find(ctx, id, nil, MAX_SEARCH_LIMIT + 1)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, pgx.ErrNoRows
-		}
-
-		s.logger.Sugar().Errorf("failed to get user(%s) follows from postgres: %s", id.String(), err.Error())
+		s.logger.Sugar().Errorf("failed to get user(%s) follows page from postgres: %s", id.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	if err := s.repo.Redis.Default.SetJSON(ctx, redisrepo.UserFollowsKey(id.String(), limit, offset), follows, time.Minute * 1); err != nil {
-		s.logger.Sugar().Errorf("failed to set user(%s) follows in redis: %s", id.String(), err.Error())
+	if err := s.repo.Redis.Default.SetJSON(ctx, firstPageKey, follows, time.Minute * 1); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) follows page in redis: %s", id.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	return follows, nil
+	return dto.NewFollowsPage(follows, limit), nil
 }
 
 func maximumLimit(limit *int) {
@@ -322,74 +1029,221 @@ func maximumLimit(limit *int) {
 	}
 }
 
-func (s *userService) Update(ctx context.Context, user model.FullUser, updates map[string]interface{}) error {
-	if len(updates) == 0 {
-		return nil
+func (s *userService) Update(ctx context.Context, user model.FullUser, req dto.UpdateProfileReq, ifUpdatedAt *time.Time) (time.Time, error) {
+	updates, err := s.validateProfileUpdate(ctx, user, req)
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	if username, ok := updates["username"]; ok {
-		exists, err := s.repo.Postgres.User.ExistsWithUsername(ctx, username.(string))
-		if err != nil {
-			s.logger.Sugar().Errorf("failed to get exists with username(%s) result from postgres: %s", username.(string), err.Error())
-			return ErrInternal
+	if len(updates) == 0 {
+		if ifUpdatedAt != nil && !user.UpdatedAt.Equal(*ifUpdatedAt) {
+			return time.Time{}, ErrPreconditionFailed
 		}
-		if exists {
-			return ErrUserWithUsernameAlreadyExists
+		return user.UpdatedAt, nil
+	}
+
+	updatedAt, err := s.repo.Postgres.User.UpdateByID(ctx, user.ID, updates, ifUpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return time.Time{}, ErrPreconditionFailed
 		}
+
+		s.logger.Sugar().Errorf("failed to update user(%s): %s", user.ID.String(), err.Error())
+		return time.Time{}, ErrInternal
 	}
 
 	// Publish RabbitMQ event to update user info cache in other microservices
 	if err := s.publishUserInfoUpdated(user.ID, updates); err != nil {
-		return err
+		return time.Time{}, err
 	}
 
 	// Clear cache
 	if err := s.deleteUserInfoCache(ctx, user); err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	if err := s.repo.Postgres.User.UpdateByID(ctx, user.ID, updates); err != nil {
-		s.logger.Sugar().Errorf("failed to update user(%s): %s", user.ID.String(), err.Error())
-		return ErrInternal
+	// Followers that were waiting for approval are let in once the account is public
+	if isPrivate, ok := updates["is_private"].(bool); ok && !isPrivate {
+		if err := s.approveAllFollowRequests(ctx, user); err != nil {
+			return time.Time{}, err
+		}
 	}
 
-	return nil
+	return updatedAt, nil
+}
+
+// validateProfileUpdate turns the merge patch into column updates, collecting
+// every rejected field instead of stopping at the first one.
+func (s *userService) validateProfileUpdate(ctx context.Context, user model.FullUser, req dto.UpdateProfileReq) (map[string]interface{}, error) {
+	updates := map[string]interface{}{}
+	fieldErrors := dto.FieldErrors{}
+
+	if req.Username.Set {
+		if req.Username.Value == nil {
+			fieldErrors["username"] = "cannot be null"
+		} else if username := normalizeUsername(*req.Username.Value); username != user.Username {
+			if reason := validateUsername(username); reason != "" {
+				fieldErrors["username"] = reason
+			} else {
+				exists, err := s.repo.Postgres.User.ExistsWithUsername(ctx, username)
+				if err != nil {
+					s.logger.Sugar().Errorf("failed to get exists with username(%s) result from postgres: %s", username, err.Error())
+					return nil, ErrInternal
+				}
+				if exists {
+					fieldErrors["username"] = ErrUserWithUsernameAlreadyExists.Error()
+				} else {
+					updates["username"] = username
+				}
+			}
+		}
+	}
+
+	if req.DisplayName.Set {
+		if value, reason := normalizeProfileText(req.DisplayName.Value, false, MAX_DISPLAY_NAME_LENGTH); reason != "" {
+			fieldErrors["display_name"] = reason
+		} else {
+			updates["display_name"] = value
+		}
+	}
+
+	if req.Bio.Set {
+		if value, reason := normalizeProfileText(req.Bio.Value, true, MAX_BIO_LENGTH); reason != "" {
+			fieldErrors["bio"] = reason
+		} else {
+			updates["bio"] = value
+		}
+	}
+
+	if req.IsPrivate.Set {
+		if req.IsPrivate.Value == nil {
+			fieldErrors["is_private"] = "cannot be null"
+		} else if *req.IsPrivate.Value != user.IsPrivate {
+			updates["is_private"] = *req.IsPrivate.Value
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+
+	return updates, nil
+}
+
+// normalizeProfileText returns nil for cleared or blank values so that they are stored as NULL.
+func normalizeProfileText(value *string, allowNewlines bool, maxLength int) (*string, string) {
+	if value == nil {
+		return nil, ""
+	}
+
+	normalized := normalizeText(*value, allowNewlines)
+	if normalized == "" {
+		return nil, ""
+	}
+
+	if utf8.RuneCountInString(normalized) > maxLength {
+		return nil, fmt.Sprintf("must be at most %d characters long", maxLength)
+	}
+
+	return &normalized, ""
 }
 
-func (s *userService) SetAvatar(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) error {
-	file, err := fileHeader.Open()
+func (s *userService) SetAvatar(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader, crop dto.AvatarCropReq) error {
+	img, err := decodeImageUpload(fileHeader)
+	if err != nil {
+		return err
+	}
+
+	square, err := cropSquare(img, crop)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	buff := make([]byte, 512)
-	if _, err := file.Read(buff); err != nil {
+	return s.saveAvatar(ctx, user, square)
+}
+
+func (s *userService) SetGeneratedAvatar(ctx context.Context, user model.FullUser) error {
+	name := user.Username
+	if user.DisplayName != nil {
+		name = *user.DisplayName
+	}
+
+	return s.saveAvatar(ctx, user, generateIdenticon(user.ID, name))
+}
+
+func (s *userService) RemoveAvatar(ctx context.Context, user model.FullUser) error {
+	return s.SetGeneratedAvatar(ctx, user)
+}
+
+// saveAvatar uploads every AVATAR_SIZES variant of the square image and points the user's avatar at them.
+func (s *userService) saveAvatar(ctx context.Context, user model.FullUser, square image.Image) error {
+	avatarURLs := make(map[string]string, len(AVATAR_SIZES))
+	for _, size := range AVATAR_SIZES {
+		content, err := encodePNG(resizeImage(square, size, size))
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to encode user(%s) avatar of size %d: %s", user.ID.String(), size, err.Error())
+			return ErrInternal
+		}
+
+		uploadPath := fmt.Sprintf("user-avatars/%s/%d", user.ID.String(), size)
+
+		// Upload avatar to BloggingApp's CDN
+		returnedURL, err := s.uploadToCDN(uploadPath, fmt.Sprintf("%d.png", size), bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+
+		avatarURLs[strconv.Itoa(size)] = returnedURL
+	}
+
+	updates := map[string]interface{}{
+		"avatar_url": avatarURLs[strconv.Itoa(AVATAR_SIZES[len(AVATAR_SIZES)-1])],
+		"avatar_urls": avatarURLs,
+	}
+	if _, err := s.repo.Postgres.User.UpdateByID(ctx, user.ID, updates, nil); err != nil {
+		s.logger.Sugar().Errorf("failed to update user(%s) avatar: %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
+	// Publish RabbitMQ event to update user info cache in other microservices
+	if err := s.publishUserInfoUpdated(user.ID, updates); err != nil {
+		return err
+	}
+
+	if err := s.deleteUserInfoCache(ctx, user); err != nil {
 		return err
 	}
 
-	if !filetype.IsImage(buff) {
-		return ErrFileMustBeImage
+	return nil
+}
+
+func (s *userService) SetBanner(ctx context.Context, user model.FullUser, fileHeader *multipart.FileHeader) error {
+	img, err := decodeImageUpload(fileHeader)
+	if err != nil {
+		return err
 	}
 
-	ext := filepath.Ext(fileHeader.Filename)
-	if ext == "" {
-		return ErrFileMustHaveValidExtension
+	banner, err := cropBanner(img)
+	if err != nil {
+		return err
 	}
 
-	uploadPath := "user-avatars/" + user.ID.String()
+	content, err := encodeJPEG(resizeImage(banner, BANNER_WIDTH, BANNER_HEIGHT))
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to encode user(%s) banner: %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
 
-	// Upload avatar to BloggingApp's CDN
-	returnedURL, err := s.uploadAvatarToCDN(uploadPath, file, fileHeader)
+	// Upload banner to BloggingApp's CDN
+	returnedURL, err := s.uploadToCDN("user-banners/" + user.ID.String(), "banner.jpg", bytes.NewReader(content))
 	if err != nil {
 		return err
 	}
 
 	updates := map[string]interface{}{
-		"avatar_url": returnedURL,
+		"banner_url": returnedURL,
 	}
-	if err := s.repo.Postgres.User.UpdateByID(ctx, user.ID, updates); err != nil {
-		s.logger.Sugar().Errorf("failed to update user(%s) avatar: %s", user.ID.String(), err.Error())
+	if _, err := s.repo.Postgres.User.UpdateByID(ctx, user.ID, updates, nil); err != nil {
+		s.logger.Sugar().Errorf("failed to update user(%s) banner: %s", user.ID.String(), err.Error())
 		return ErrInternal
 	}
 	// Publish RabbitMQ event to update user info cache in other microservices
@@ -404,7 +1258,8 @@ func (s *userService) SetAvatar(ctx context.Context, user model.FullUser, fileHe
 	return nil
 }
 
-func (s *userService) uploadAvatarToCDN(path string, file multipart.File, fileHeader *multipart.FileHeader) (string, error) {
+// uploadToCDN uploads the file to the given asset path and returns its URL.
+func (s *userService) uploadToCDN(path string, filename string, file io.Reader) (string, error) {
 	endpoint := "/upload"
 	url := viper.GetString("cdn.origin") + endpoint
 
@@ -423,17 +1278,12 @@ func (s *userService) uploadAvatarToCDN(path string, file multipart.File, fileHe
 	}
 
 	// Writing file
-	fileWriter, err := writer.CreateFormFile("file", fileHeader.Filename)
+	fileWriter, err := writer.CreateFormFile("file", filename)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to create file part for CDN request: %s", err.Error())
 		return "", ErrInternal
 	}
 
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		s.logger.Sugar().Errorf("failed to seek to the start of the file: %s", err.Error())
-		return "", ErrInternal
-	}
-
 	if _, err := io.Copy(fileWriter, file); err != nil {
 		s.logger.Sugar().Errorf("failed to copy file content for CDN request: %s", err.Error())
 		return "", ErrInternal
@@ -473,7 +1323,7 @@ func (s *userService) uploadAvatarToCDN(path string, file multipart.File, fileHe
         } else {
             s.logger.Sugar().Errorf("ERROR from CDN endpoint(%s), code(%d), details: %s", endpoint, resp.StatusCode, bodyJSON["details"])
         }
-        return "", ErrFailedToUploadAvatarToCDN
+        return "", ErrFailedToUploadToCDN
 	}
 
 	return string(body), nil
@@ -495,43 +1345,94 @@ func (s *userService) publishUserInfoUpdated(userID uuid.UUID, updates map[strin
 	return nil
 }
 
-func (s *userService) AddSocialLink(ctx context.Context, user model.FullUser, link string) error {
-	if len(user.SocialLinks) >= MAX_SOCIAL_LINKS_COUNT {
+func (s *userService) AddSocialLink(ctx context.Context, user model.FullUser, req dto.AddSocialLinkReq) error {
+	if len(user.SocialLinks) >= s.socialLinks.MaxCount() {
 		return ErrMaxSocialLinksAchieved
 	}
 
-	verifier := urlverifier.NewVerifier()
-	verifier.DisableHTTPCheck()
-	verifier.DisallowHTTPCheckInternal()
-	result, err := verifier.Verify(link)
+	match, err := s.matchSocialLink(req.URL)
 	if err != nil {
 		return err
 	}
 
-	if !result.HTTP.IsSuccess {
-		return fmt.Errorf("the url is reachable with status code: %d", result.HTTP.StatusCode)
+	label, reason := normalizeProfileText(req.Label, false, MAX_SOCIAL_LINK_LABEL_LENGTH)
+	if reason != "" {
+		return dto.FieldErrors{"label": reason}
 	}
 
-	linkPlatform, err := s.defineSocialLinkType(link)
-	if err != nil {
+	for _, l := range user.SocialLinks {
+		if l.Platform == match.Platform.Name {
+			return ErrSocialLinkAlreadySet
+		}
+	}
+
+	newLink := model.SocialLink{
+		UserID: user.ID,
+		URL: match.URL,
+		Platform: match.Platform.Name,
+		Label: label,
+	}
+	if err := s.repo.Postgres.User.AddSocialLink(ctx, newLink); err != nil {
+		s.logger.Sugar().Errorf("failed to add social link for user(%s): %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	s.socialLinkVerification.Enqueue(model.SocialLinkToVerify{SocialLink: newLink, Username: user.Username})
+
+	if err := s.deleteUserInfoCache(ctx, user); err != nil {
 		return err
 	}
 
+	return nil
+}
+
+func (s *userService) UpdateSocialLink(ctx context.Context, user model.FullUser, platform string, req dto.UpdateSocialLinkReq) error {
+	var link *model.SocialLink
 	for _, l := range user.SocialLinks {
-		if l.Platform == linkPlatform {
-			return fmt.Errorf("link with type '%s' has already been set", l.Platform)
+		if l.Platform == platform {
+			link = l
+			break
 		}
 	}
+	if link == nil {
+		return ErrSocialLinkNotFound
+	}
 
-	if err := s.repo.Postgres.User.AddSocialLink(ctx, model.SocialLink{
-		UserID: user.ID,
-		URL: link,
-		Platform: linkPlatform,
-	}); err != nil {
-		s.logger.Sugar().Errorf("failed to add social link for user(%s): %s", user.ID.String(), err.Error())
+	updatedLink := *link
+	if req.URL.Set {
+		if req.URL.Value == nil {
+			return ErrSocialLinkURLCannotBeNull
+		}
+
+		match, err := s.matchSocialLink(*req.URL.Value)
+		if err != nil {
+			return err
+		}
+		if match.Platform.Name != platform {
+			return ErrSocialLinkPlatformMismatch
+		}
+
+		updatedLink.URL = match.URL
+	}
+
+	if req.Label.Set {
+		label, reason := normalizeProfileText(req.Label.Value, false, MAX_SOCIAL_LINK_LABEL_LENGTH)
+		if reason != "" {
+			return dto.FieldErrors{"label": reason}
+		}
+
+		updatedLink.Label = label
+	}
+
+	if err := s.repo.Postgres.User.UpdateSocialLink(ctx, updatedLink); err != nil {
+		s.logger.Sugar().Errorf("failed to update user(%s) social link(%s): %s", user.ID.String(), platform, err.Error())
 		return ErrInternal
 	}
 
+	if updatedLink.URL != link.URL {
+		s.socialLinkVerification.Enqueue(model.SocialLinkToVerify{SocialLink: updatedLink, Username: user.Username})
+	}
+
 	if err := s.deleteUserInfoCache(ctx, user); err != nil {
 		return err
 	}
@@ -539,19 +1440,60 @@ func (s *userService) AddSocialLink(ctx context.Context, user model.FullUser, li
 	return nil
 }
 
-func (s *userService) defineSocialLinkType(link string) (string, error) {
-	typ := ""
-	for uri, t := range s.socialLinkTypes {
-		if strings.HasPrefix(link, uri) {
-			typ = t
+func (s *userService) ReorderSocialLinks(ctx context.Context, user model.FullUser, platforms []string) error {
+	if len(platforms) != len(user.SocialLinks) {
+		return ErrInvalidSocialLinksOrder
+	}
+
+	userPlatforms := make(map[string]bool, len(user.SocialLinks))
+	for _, link := range user.SocialLinks {
+		userPlatforms[link.Platform] = false
+	}
+	for _, platform := range platforms {
+		listed, ok := userPlatforms[platform]
+		if !ok || listed {
+			return ErrInvalidSocialLinksOrder
 		}
+		userPlatforms[platform] = true
+	}
+
+	if err := s.repo.Postgres.User.ReorderSocialLinks(ctx, user.ID, platforms); err != nil {
+		s.logger.Sugar().Errorf("failed to reorder user(%s) social links: %s", user.ID.String(), err.Error())
+		return ErrInternal
+	}
+
+	if err := s.deleteUserInfoCache(ctx, user); err != nil {
+		return err
 	}
 
-	if typ == "" {
-		return "", ErrLinkHasInvalidType
+	return nil
+}
+
+func (s *userService) matchSocialLink(link string) (*sociallink.Match, error) {
+	match, err := s.socialLinks.Match(link)
+	if err != nil {
+		if err == sociallink.ErrUnknownPlatform {
+			return nil, ErrLinkHasInvalidType
+		}
+		return nil, ErrLinkIsNotValidURL
 	}
 
-	return typ, nil
+	return match, nil
+}
+
+// decorateSocialLinks fills in the platform details from the registry, links of
+// platforms that were removed from the registry are left as they are.
+func (s *userService) decorateSocialLinks(links []*model.SocialLink) {
+	for _, link := range links {
+		platform, ok := s.socialLinks.Platform(link.Platform)
+		if !ok {
+			continue
+		}
+
+		link.DisplayName = platform.DisplayName
+		link.Icon = platform.Icon
+		link.Handle = s.socialLinks.Handle(link.Platform, link.URL)
+	}
 }
 
 func (s *userService) DeleteSocialLink(ctx context.Context, user model.FullUser, platform string) error {
@@ -0,0 +1,201 @@
+package sociallink
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/BloggingApp/user-service/internal/config"
+)
+
+var (
+	ErrInvalidURL = errors.New("the link is not a valid https url")
+	ErrUnknownPlatform = errors.New("the link has invalid type")
+)
+
+type Platform struct {
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+	Icon          string `json:"icon"`
+	pattern       *regexp.Regexp
+	stripQuery    bool
+	lowercasePath bool
+}
+
+// Match is a link normalized and recognized by the registry.
+type Match struct {
+	Platform *Platform
+	URL      string
+	Handle   string
+}
+
+// Registry recognizes social links by the platforms configured in app.yaml.
+// Platforms are tried in their configured order and the first match wins,
+// so catch-all platforms such as personal websites must come last.
+type Registry struct {
+	platforms      []*Platform
+	byName         map[string]*Platform
+	trackingParams []string
+	maxCount       int
+}
+
+func NewRegistry(cfg config.SocialLinksConfig) (*Registry, error) {
+	if cfg.MaxCount <= 0 {
+		return nil, errors.New("social links max_count must be positive")
+	}
+
+	r := &Registry{
+		byName: make(map[string]*Platform, len(cfg.Platforms)),
+		trackingParams: cfg.TrackingParams,
+		maxCount: cfg.MaxCount,
+	}
+
+	for _, platformCfg := range cfg.Platforms {
+		if platformCfg.Name == "" {
+			return nil, errors.New("social link platform must have a name")
+		}
+		if _, exists := r.byName[platformCfg.Name]; exists {
+			return nil, fmt.Errorf("social link platform(%s) is defined twice", platformCfg.Name)
+		}
+
+		pattern, err := regexp.Compile(platformCfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of social link platform(%s): %w", platformCfg.Name, err)
+		}
+
+		platform := &Platform{
+			Name: platformCfg.Name,
+			DisplayName: platformCfg.DisplayName,
+			Icon: platformCfg.Icon,
+			pattern: pattern,
+			stripQuery: platformCfg.StripQuery,
+			lowercasePath: platformCfg.LowercasePath,
+		}
+		r.platforms = append(r.platforms, platform)
+		r.byName[platform.Name] = platform
+	}
+
+	return r, nil
+}
+
+func (r *Registry) MaxCount() int {
+	return r.maxCount
+}
+
+func (r *Registry) Platforms() []*Platform {
+	return r.platforms
+}
+
+func (r *Registry) Platform(name string) (*Platform, bool) {
+	platform, ok := r.byName[name]
+	return platform, ok
+}
+
+// Match normalizes the link and returns the first platform that recognizes it.
+func (r *Registry) Match(link string) (*Match, error) {
+	u, err := r.normalize(link)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, platform := range r.platforms {
+		platformURL := *u
+		if platform.stripQuery {
+			platformURL.RawQuery = ""
+		}
+		if platform.lowercasePath {
+			platformURL.Path = strings.ToLower(platformURL.Path)
+			platformURL.RawPath = ""
+		}
+
+		normalized := platformURL.String()
+		if handle, ok := platform.match(normalized); ok {
+			return &Match{
+				Platform: platform,
+				URL: normalized,
+				Handle: handle,
+			}, nil
+		}
+	}
+
+	return nil, ErrUnknownPlatform
+}
+
+// Handle extracts the handle of an already stored link of the platform.
+func (r *Registry) Handle(platformName string, link string) string {
+	platform, ok := r.byName[platformName]
+	if !ok {
+		return ""
+	}
+
+	handle, _ := platform.match(link)
+	return handle
+}
+
+func (p *Platform) match(link string) (string, bool) {
+	submatches := p.pattern.FindStringSubmatch(link)
+	if submatches == nil {
+		return "", false
+	}
+
+	if i := p.pattern.SubexpIndex("handle"); i > 0 {
+		return submatches[i], true
+	}
+	return "", true
+}
+
+// normalize enforces https, lowercases the host and drops the default port,
+// the fragment, the trailing slash and tracking query parameters.
+func (r *Registry) normalize(link string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" || u.User != nil {
+		return nil, ErrInvalidURL
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		u.Scheme = "https"
+	default:
+		return nil, ErrInvalidURL
+	}
+
+	u.Host = strings.ToLower(strings.TrimSuffix(u.Host, ":443"))
+	u.Host = strings.TrimSuffix(u.Host, ":80")
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	} else {
+		u.Path = ""
+		u.RawPath = ""
+	}
+
+	query := u.Query()
+	for param := range query {
+		if r.isTrackingParam(param) {
+			query.Del(param)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	return u, nil
+}
+
+func (r *Registry) isTrackingParam(param string) bool {
+	param = strings.ToLower(param)
+	for _, tracking := range r.trackingParams {
+		if prefix, ok := strings.CutSuffix(tracking, "*"); ok {
+			if strings.HasPrefix(param, prefix) {
+				return true
+			}
+		} else if param == tracking {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package sociallink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const MAX_VERIFIED_PAGE_SIZE = 1 << 20
+
+var errInternalAddress = errors.New("refusing to connect to an internal address")
+
+// Verifier checks that a linked page links back to the user's profile with rel="me".
+type Verifier struct {
+	client *http.Client
+}
+
+func NewVerifier(client *http.Client) *Verifier {
+	return &Verifier{
+		client: client,
+	}
+}
+
+// NewHTTPClient returns a client for fetching user-supplied links, it refuses
+// to connect to loopback, private and other internal addresses.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+				return errInternalAddress
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+	}
+}
+
+// Verify fetches link and reports whether the page has an <a> or <link> with
+// rel="me" pointing to profileURL. An error means that the page couldn't be
+// fetched, so whether it links back is unknown.
+func (v *Verifier) Verify(ctx context.Context, link string, profileURL string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return false, nil
+	}
+
+	// Links are resolved against the final URL in case of redirects
+	return hasRelMeLink(io.LimitReader(resp.Body, MAX_VERIFIED_PAGE_SIZE), resp.Request.URL, profileURL), nil
+}
+
+func hasRelMeLink(body io.Reader, base *url.URL, profileURL string) bool {
+	want := comparableURL(profileURL)
+
+	tokenizer := html.NewTokenizer(body)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "a" && token.Data != "link" {
+				continue
+			}
+
+			var rel, href string
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "rel":
+					rel = attr.Val
+				case "href":
+					href = attr.Val
+				}
+			}
+
+			if !hasRelMe(rel) || href == "" {
+				continue
+			}
+
+			resolved, err := base.Parse(href)
+			if err != nil {
+				continue
+			}
+
+			if comparableURL(resolved.String()) == want {
+				return true
+			}
+		}
+	}
+}
+
+func hasRelMe(rel string) bool {
+	for _, value := range strings.Fields(rel) {
+		if strings.EqualFold(value, "me") {
+			return true
+		}
+	}
+	return false
+}
+
+// comparableURL ignores the scheme, the host's case, the query, the fragment and the trailing slash.
+func comparableURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+
+	return strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/")
+}
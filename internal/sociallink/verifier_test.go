@@ -0,0 +1,196 @@
+package sociallink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testProfileURL = "https://blogging.app/@alice"
+
+func TestHasRelMeLink(t *testing.T) {
+	base, _ := url.Parse("https://alice.dev/about/")
+
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "a with rel me",
+			body: `<a rel="me" href="https://blogging.app/@alice">Blog</a>`,
+			want: true,
+		},
+		{
+			name: "link with rel me",
+			body: `<head><link rel="me" href="https://blogging.app/@alice"></head>`,
+			want: true,
+		},
+		{
+			name: "rel me among other values",
+			body: `<a rel="nofollow ME noopener" href="https://blogging.app/@alice">Blog</a>`,
+			want: true,
+		},
+		{
+			name: "scheme, host case and trailing slash ignored",
+			body: `<a rel="me" href="http://Blogging.App/@alice/?ref=bio#top">Blog</a>`,
+			want: true,
+		},
+		{
+			name: "relative href resolved against the page",
+			body: `<a rel="me" href="//blogging.app/@alice">Blog</a>`,
+			want: true,
+		},
+		{
+			name: "without rel me",
+			body: `<a href="https://blogging.app/@alice">Blog</a>`,
+			want: false,
+		},
+		{
+			name: "rel me to another profile",
+			body: `<a rel="me" href="https://blogging.app/@alice2">Blog</a>`,
+			want: false,
+		},
+		{
+			name: "rel me on another tag",
+			body: `<img rel="me" src="https://blogging.app/@alice">`,
+			want: false,
+		},
+		{
+			name: "relative href to the page's host",
+			body: `<a rel="me" href="/@alice">Blog</a>`,
+			want: false,
+		},
+		{
+			name: "empty page",
+			body: ``,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasRelMeLink(strings.NewReader(tt.body), base, testProfileURL); got != tt.want {
+				t.Errorf("hasRelMeLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "backlink",
+			status: http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body: `<a rel="me" href="https://blogging.app/@alice">Blog</a>`,
+			want: true,
+		},
+		{
+			name: "no backlink",
+			status: http.StatusOK,
+			contentType: "text/html",
+			body: `<a href="https://blogging.app/@alice">Blog</a>`,
+			want: false,
+		},
+		{
+			name: "not html",
+			status: http.StatusOK,
+			contentType: "application/json",
+			body: `{"rel": "me"}`,
+			want: false,
+		},
+		{
+			name: "server error",
+			status: http.StatusServiceUnavailable,
+			contentType: "text/html",
+			body: `<a rel="me" href="https://blogging.app/@alice">Blog</a>`,
+			wantErr: true,
+		},
+		{
+			name: "not found",
+			status: http.StatusNotFound,
+			contentType: "text/html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := NewVerifier(srv.Client()).Verify(context.Background(), srv.URL, testProfileURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyFollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a rel="me" href="https://blogging.app/@alice">Blog</a>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	verified, err := NewVerifier(srv.Client()).Verify(context.Background(), srv.URL + "/old", testProfileURL)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !verified {
+		t.Error("Verify() = false, want true")
+	}
+}
+
+func TestVerifyTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewVerifier(srv.Client()).Verify(ctx, srv.URL, testProfileURL); err == nil {
+		t.Error("Verify() error = nil, want the context's error")
+	}
+}
+
+func TestNewHTTPClientRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<a rel="me" href="https://blogging.app/@alice">Blog</a>`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewVerifier(NewHTTPClient(0)).Verify(context.Background(), srv.URL, testProfileURL); err == nil {
+		t.Error("Verify() error = nil, want a refused connection")
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN banner_url;
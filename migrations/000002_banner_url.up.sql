@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN banner_url text;
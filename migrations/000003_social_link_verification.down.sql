@@ -0,0 +1,8 @@
+DROP INDEX social_links_next_check_at_idx;
+
+ALTER TABLE social_links
+DROP COLUMN verified,
+DROP COLUMN verified_at,
+DROP COLUMN checked_at,
+DROP COLUMN failed_checks,
+DROP COLUMN next_check_at;
@@ -0,0 +1,8 @@
+ALTER TABLE social_links
+ADD COLUMN verified boolean NOT NULL DEFAULT false,
+ADD COLUMN verified_at timestamptz,
+ADD COLUMN checked_at timestamptz,
+ADD COLUMN failed_checks integer NOT NULL DEFAULT 0,
+ADD COLUMN next_check_at timestamptz;
+
+CREATE INDEX social_links_next_check_at_idx ON social_links (next_check_at NULLS FIRST);
@@ -0,0 +1,3 @@
+ALTER TABLE social_links
+DROP COLUMN position,
+DROP COLUMN label;
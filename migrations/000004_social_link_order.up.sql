@@ -0,0 +1,12 @@
+ALTER TABLE social_links
+ADD COLUMN position integer NOT NULL DEFAULT 0,
+ADD COLUMN label text;
+
+-- The links have no creation time and were listed unordered, so the existing
+-- ones are ordered by platform name, which is stable and the same for every user
+UPDATE social_links l SET position = o.position
+FROM (
+	SELECT user_id, platform, row_number() OVER (PARTITION BY user_id ORDER BY platform) - 1 AS position
+	FROM social_links
+) o
+WHERE l.user_id = o.user_id AND l.platform = o.platform;
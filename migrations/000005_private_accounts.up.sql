@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN is_private boolean NOT NULL DEFAULT false;
+
+CREATE TABLE follow_requests (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	follower_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, follower_id)
+);
+
+CREATE INDEX follow_requests_user_id_created_at_idx ON follow_requests (user_id, created_at DESC);
@@ -0,0 +1,8 @@
+CREATE TABLE blocks (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	blocked_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, blocked_id)
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
@@ -0,0 +1,9 @@
+CREATE TABLE mutes (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	muted_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	expires_at timestamptz,
+	PRIMARY KEY (user_id, muted_id)
+);
+
+CREATE INDEX mutes_muted_id_idx ON mutes (muted_id);
@@ -0,0 +1,3 @@
+DROP TABLE reports;
+
+ALTER TABLE users DROP COLUMN suspended_until;
@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN suspended_until timestamptz;
+
+CREATE TABLE reports (
+	id uuid PRIMARY KEY,
+	reporter_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	target_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	category text NOT NULL,
+	text text,
+	status text NOT NULL,
+	moderator_id uuid REFERENCES users(id) ON DELETE SET NULL,
+	note text,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	UNIQUE (reporter_id, target_id)
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at);
+CREATE INDEX reports_target_id_idx ON reports (target_id);
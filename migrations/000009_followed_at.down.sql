@@ -0,0 +1,4 @@
+DROP INDEX followers_follower_id_followed_at_idx;
+DROP INDEX followers_user_id_followed_at_idx;
+
+ALTER TABLE followers DROP COLUMN followed_at;
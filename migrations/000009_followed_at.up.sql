@@ -0,0 +1,4 @@
+ALTER TABLE followers ADD COLUMN followed_at timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX followers_user_id_followed_at_idx ON followers (user_id, followed_at DESC, follower_id DESC);
+CREATE INDEX followers_follower_id_followed_at_idx ON followers (follower_id, followed_at DESC, user_id DESC);
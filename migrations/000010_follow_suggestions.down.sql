@@ -0,0 +1,7 @@
+DROP TABLE suggestion_dismissals;
+DROP TABLE follow_suggestions;
+
+DROP INDEX users_last_active_at_idx;
+DROP INDEX users_followers_idx;
+
+ALTER TABLE users DROP COLUMN last_active_at;
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN last_active_at timestamptz;
+
+CREATE INDEX users_followers_idx ON users (followers DESC);
+CREATE INDEX users_last_active_at_idx ON users (last_active_at DESC) WHERE last_active_at IS NOT NULL;
+
+CREATE TABLE follow_suggestions (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	suggested_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	score double precision NOT NULL,
+	reason text NOT NULL,
+	computed_at timestamptz NOT NULL,
+	PRIMARY KEY (user_id, suggested_id)
+);
+
+CREATE TABLE suggestion_dismissals (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	dismissed_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	dismissed_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, dismissed_id)
+);
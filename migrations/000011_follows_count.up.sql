@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN follows bigint NOT NULL DEFAULT 0;
+
+UPDATE users u SET follows = c.follows
+FROM (SELECT follower_id, count(*) AS follows FROM followers GROUP BY follower_id) c
+WHERE u.id = c.follower_id;
@@ -0,0 +1,8 @@
+ALTER TABLE followers ADD COLUMN new_post_notifications_enabled boolean NOT NULL DEFAULT true;
+
+UPDATE followers SET new_post_notifications_enabled = notification_level <> 'none';
+
+ALTER TABLE followers
+DROP COLUMN notification_level,
+DROP COLUMN notification_channels,
+DROP COLUMN notification_digest;
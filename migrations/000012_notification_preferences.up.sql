@@ -0,0 +1,9 @@
+ALTER TABLE followers
+ADD COLUMN notification_level text NOT NULL DEFAULT 'all',
+ADD COLUMN notification_channels text[] NOT NULL DEFAULT '{in_app}',
+ADD COLUMN notification_digest text NOT NULL DEFAULT 'immediate';
+
+-- Followers that turned the notifications off keep them off
+UPDATE followers SET notification_level = 'none' WHERE NOT new_post_notifications_enabled;
+
+ALTER TABLE followers DROP COLUMN new_post_notifications_enabled;
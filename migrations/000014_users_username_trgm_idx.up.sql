@@ -0,0 +1,2 @@
+-- A failed concurrent build leaves an INVALID index behind, it is dropped by the down migration
+CREATE INDEX CONCURRENTLY users_username_trgm_idx ON users USING gin (username gin_trgm_ops);
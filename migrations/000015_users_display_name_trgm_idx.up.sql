@@ -0,0 +1,2 @@
+-- A failed concurrent build leaves an INVALID index behind, it is dropped by the down migration
+CREATE INDEX CONCURRENTLY users_display_name_trgm_idx ON users USING gin (display_name gin_trgm_ops);